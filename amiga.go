@@ -0,0 +1,99 @@
+package helper
+
+// Package file amiga.go contains the detection of the Commodore Amiga texts and ANSI art.
+
+import (
+	"github.com/Defacto2/helper/codepage"
+	"github.com/Defacto2/helper/sauce"
+)
+
+const (
+	RuleAmiga     Rule = "amiga sequence" // an Amiga CSI control sequence or an ANSI sequence only used by the Amiga console
+	RuleAmigaFont Rule = "amiga font"     // a SAUCE record that uses an Amiga font, such as Topaz or MicroKnight
+)
+
+// console matches the control sequences of the Amiga console device.
+// These use the 0x9b control sequence introducer in place of ESC [,
+// or they are ANSI sequences for the cursor and the background color that are not used on MS-DOS.
+func (x *examination) console(p []byte) {
+	const escape = 0x1b
+	for i, char := range p {
+		switch {
+		case char == codepage.CSI && csi(p[i+1:]):
+			x.add(RuleAmiga, i, p[i:i+1], codepage.Topaz)
+		case char == escape:
+			_, next := neighbours(p, i)
+			if next != '[' {
+				continue
+			}
+			if n := amigaSequence(p[i+2:]); n > 0 {
+				x.add(RuleAmiga, i, p[i:i+2+n], codepage.Topaz)
+			}
+		}
+	}
+}
+
+// font matches a SAUCE record of the text that uses an Amiga font.
+// The Amiga font, such as MicroKnight, is used as the encoding of the Amiga text.
+func (x *examination) font(p []byte) {
+	if x.wide != nil || x.home != nil {
+		return
+	}
+	rec, err := sauce.Decode(p)
+	if err != nil {
+		return
+	}
+	if x.topaz = codepage.Amiga(rec.Font); x.topaz != nil {
+		x.add(RuleAmigaFont, len(p)-sauce.Size, []byte(rec.Font), x.topaz)
+	}
+}
+
+// amiga returns true if the text uses an Amiga font, or the Amiga control sequences
+// without any CP-437 sequences.
+func (x *examination) amiga() bool {
+	return x.tally[RuleAmigaFont] > 0 || (x.tally[RuleAmiga] > 0 && x.tally[RuleSequence] == 0)
+}
+
+// amigaFont returns the Amiga character set of the text, which is Topaz unless the SAUCE font is another.
+func (x *examination) amigaFont() *codepage.Charmap {
+	if x.topaz != nil {
+		return x.topaz
+	}
+	return codepage.Topaz
+}
+
+// amigaSequence returns the length of the parameters and final byte of an ANSI sequence
+// that is only used by the Amiga console device following the ESC [ characters, or 0 for any other sequence.
+// These are the cursor on and off, " p", and the background color, ">m".
+// The page length, line length and offsets of the Amiga, "t", "u", "x" and "y", are not matched,
+// as the same final bytes are used by PC sequences, such as the PabloDraw 24-bit color, ESC [ 1 ; R ; G ; B t.
+func amigaSequence(p []byte) int {
+	const maxParams = 16
+	digits, private := 0, false
+	for i, char := range p {
+		switch {
+		case i > maxParams:
+			return 0
+		case i == 0 && char == '>':
+			private = true
+		case char >= '0' && char <= '9':
+			digits++
+		case char == ';':
+			continue
+		case char == ' ':
+			_, next := neighbours(p, i)
+			if next == 'p' && !private {
+				return i + 2
+			}
+			return 0
+		case char == 'm':
+			if private && digits > 0 {
+				return i + 1
+			}
+			return 0
+		default:
+			return 0
+		}
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package helper_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/codepage"
+	"github.com/Defacto2/helper/sauce"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestDetectAmiga(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		text   string
+		expect encoding.Encoding
+	}{
+		{"csi", "\x9b0;33mAmiga \x9b1mrules\x9b0m\n", codepage.Topaz},
+		{"cursor off", "\x1b[0 p\x1b[1;32mGreetings to all\x1b[0m\n", codepage.Topaz},
+		{"background", "\x1b[>4m\x1b[33mHello\n", codepage.Topaz},
+		{"page length", "\x9b25tCaf\xe9 \x7f\n", codepage.Topaz},
+		{"pablodraw true color", "\x1b[1;255;0;0tHello\r\n\x1b[0;40;37mworld\r\n", charmap.ISO8859_1},
+		{"pablodraw black", "\x1b[0;1;31mHello\r\n\x1b[1;0;0;0tworld\r\n", charmap.ISO8859_1},
+		{"xterm window", "\x1b[8;25;80tHello\x1b[0m\r\n", charmap.ISO8859_1},
+		{"pc ansi", "\x1b[1;33mHello\x1b[u\x1b[0m\r\n", charmap.ISO8859_1},
+		{"cp-437 art", "\x9b0;33m\xdb\xdb\xdb\xdb\xb0\xb1\xb2\n", charmap.CodePage437},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d, err := helper.Detect(strings.NewReader(tt.text))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, d.Encoding(), "wanted %s but got %s", tt.expect, d.Encoding())
+		})
+	}
+}
+
+func TestDetectAmigaFont(t *testing.T) {
+	t.Parallel()
+	p, err := sauce.Append([]byte("Hello world\n"), sauce.Record{Font: "Amiga MicroKnight+"})
+	require.NoError(t, err)
+	d, err := helper.Detect(strings.NewReader(string(p)))
+	require.NoError(t, err)
+	assert.Equal(t, codepage.MicroKnight, d.Encoding())
+	assert.Equal(t, 1, d.Tally[helper.RuleAmigaFont])
+	last := d.Evidence[len(d.Evidence)-1]
+	assert.Equal(t, "Amiga MicroKnight+", string(last.Value))
+
+	p, err = sauce.Append([]byte("Hello world\r\n"), sauce.Record{Font: "IBM VGA"})
+	require.NoError(t, err)
+	d, err = helper.Detect(strings.NewReader(string(p)))
+	require.NoError(t, err)
+	assert.Zero(t, d.Tally[helper.RuleAmigaFont])
+}
+
+func TestNewUTF8ReaderAmiga(t *testing.T) {
+	t.Parallel()
+	r, err := helper.NewUTF8Reader(strings.NewReader("\x9b1mCaf\xe9\x9b0m \x7f\x7f\n"), helper.UTF8Options{})
+	require.NoError(t, err)
+	assert.Equal(t, codepage.Topaz, r.Encoding())
+	p, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "\u009b1mCafé\u009b0m \U0001FB99\U0001FB99\n", string(p))
+}
@@ -0,0 +1,304 @@
+// Package ansi tokenizes and strips the ANSI escape sequences of text art and BBS captures.
+//
+// The sequences are the control sequence introducer (CSI) sequences of the [ECMA-48] standard
+// that were implemented by the MS-DOS ANSI.SYS driver and the BBS terminals. This includes the
+// select graphic rendition (SGR) colors, cursor movements, erase functions and ANSI music,
+// plus the 8-bit, 0x9b CSI introducer that is used by the Commodore Amiga.
+//
+// [ECMA-48]: https://ecma-international.org/publications-and-standards/standards/ecma-48/
+package ansi
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+const (
+	ESC = 0x1b // ESC is the escape control character.
+	CSI = 0x9b // CSI is the 8-bit control sequence introducer used by the Amiga.
+	BEL = 0x07 // BEL is the bell control character.
+	BS  = 0x08 // BS is the backspace control character.
+	HT  = 0x09 // HT is the horizontal tab control character.
+	LF  = 0x0a // LF is the line feed control character.
+	FF  = 0x0c // FF is the form feed control character, used to clear the screen.
+	CR  = 0x0d // CR is the carriage return control character.
+	SO  = 0x0e // SO is the shift out control character, used to end ANSI music.
+	SUB = 0x1a // SUB is the substitute character, used as the MS-DOS end-of-file marker.
+)
+
+// MaxMusic is the maximum length of an ANSI music sequence, excluding its introducer.
+const MaxMusic = 1024
+
+// MaxParam is the maximum value of a numeric parameter, any larger values are reduced to it,
+// so the cursor movements of untrusted texts cannot overflow.
+const MaxParam = 9999
+
+// Kind is the kind of a token.
+type Kind uint8
+
+const (
+	Text     Kind = iota // Text is a run of printable characters.
+	Control              // Control is a single control character, such as a carriage return or line feed.
+	Sequence             // Sequence is a control sequence, such as ESC [ 1 ; 3 1 m.
+	Escape               // Escape is a two character escape sequence, such as ESC c.
+	Command              // Command is an operating system command string, such as ESC ] 0 ; title BEL.
+	Music                // Music is an ANSI music sequence, such as ESC [ M F T 1 2 0 C D E SO.
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case Text:
+		return "text"
+	case Control:
+		return "control"
+	case Sequence:
+		return "sequence"
+	case Escape:
+		return "escape"
+	case Command:
+		return "command"
+	case Music:
+		return "music"
+	}
+	return "kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Token is a lexical token of an ANSI text.
+type Token struct {
+	Kind    Kind   // Kind is the kind of token.
+	Raw     []byte // Raw is the original bytes of the token, which share the memory of the input.
+	Final   byte   // Final is the final byte of a sequence, the second byte of an escape, or 0 when incomplete.
+	Private byte   // Private is the private parameter prefix of a sequence, such as '?', or 0.
+	Params  []int  // Params are the numeric parameters of a sequence, where an omitted parameter is -1.
+}
+
+// Param returns the numeric parameter at index i of a sequence,
+// or def if the parameter is omitted or is 0.
+func (t Token) Param(i, def int) int {
+	if i >= len(t.Params) || t.Params[i] <= 0 {
+		return def
+	}
+	return t.Params[i]
+}
+
+// Lexer splits an ANSI text into tokens.
+type Lexer struct {
+	p   []byte
+	pos int
+}
+
+// NewLexer returns a lexer for the text of p.
+func NewLexer(p []byte) *Lexer {
+	return &Lexer{p: p}
+}
+
+// Tokens returns all of the tokens of the text of p.
+func Tokens(p []byte) []Token {
+	var tokens []Token
+	l := NewLexer(p)
+	for {
+		t, ok := l.Next()
+		if !ok {
+			return tokens
+		}
+		tokens = append(tokens, t)
+	}
+}
+
+// Offset returns the position of the next token in the text.
+func (l *Lexer) Offset() int {
+	return l.pos
+}
+
+// Next returns the next token of the text, or false when there are no more tokens.
+func (l *Lexer) Next() (Token, bool) {
+	if l.pos >= len(l.p) {
+		return Token{}, false
+	}
+	start := l.pos
+	char := l.p[start]
+	switch {
+	case char == ESC:
+		return l.escape(), true
+	case char == CSI && l.amiga():
+		return l.sequence(start, start+1), true
+	case control(char):
+		l.pos++
+		return Token{Kind: Control, Raw: l.p[start:l.pos], Final: char}, true
+	}
+	l.pos++
+	for l.pos < len(l.p) {
+		c := l.p[l.pos]
+		if c == ESC || control(c) || (c == CSI && l.amiga()) {
+			break
+		}
+		l.pos++
+	}
+	return Token{Kind: Text, Raw: l.p[start:l.pos]}, true
+}
+
+// control returns true for the control characters that are interpreted by ANSI.SYS.
+// The other C0 control characters are printed as glyphs by MS-DOS and are treated as text.
+func control(char byte) bool {
+	switch char {
+	case BEL, BS, HT, LF, FF, CR:
+		return true
+	}
+	return false
+}
+
+// amiga returns true if the 0x9b character at the position of the lexer is an Amiga CSI.
+// In CP-437 the character is the cent sign ¢, so to be a CSI it must be followed by
+// at least one numeric parameter and a final byte.
+func (l *Lexer) amiga() bool {
+	i := l.pos + 1
+	digits := 0
+	for ; i < len(l.p); i++ {
+		c := l.p[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+			continue
+		case c == ';':
+			continue
+		}
+		break
+	}
+	if digits == 0 || i >= len(l.p) {
+		return false
+	}
+	c := l.p[i]
+	return (c >= 0x20 && c <= 0x2f) || (c >= 0x40 && c <= 0x7e)
+}
+
+// escape returns the token of an escape character and the characters that follow it.
+func (l *Lexer) escape() Token {
+	start := l.pos
+	if start+1 >= len(l.p) {
+		l.pos++
+		return Token{Kind: Escape, Raw: l.p[start:l.pos]}
+	}
+	switch next := l.p[start+1]; {
+	case next == '[':
+		return l.sequence(start, start+2)
+	case next == ']':
+		return l.command(start)
+	case next >= 0x20 && next <= 0x7e:
+		l.pos += 2
+		return Token{Kind: Escape, Raw: l.p[start:l.pos], Final: next}
+	}
+	// a lone escape character
+	l.pos++
+	return Token{Kind: Escape, Raw: l.p[start:l.pos]}
+}
+
+// sequence returns the token of a control sequence, where body is the index after the introducer.
+func (l *Lexer) sequence(start, body int) Token {
+	t := Token{Kind: Sequence}
+	i := body
+	if i < len(l.p) && l.p[i] >= '<' && l.p[i] <= '?' {
+		t.Private = l.p[i]
+		i++
+	}
+	params := i
+	for i < len(l.p) && l.p[i] >= 0x30 && l.p[i] <= 0x3f {
+		i++
+	}
+	t.Params = parse(l.p[params:i])
+	for i < len(l.p) && l.p[i] >= 0x20 && l.p[i] <= 0x2f {
+		i++
+	}
+	if i < len(l.p) && l.p[i] >= 0x40 && l.p[i] <= 0x7e {
+		t.Final = l.p[i]
+		i++
+	}
+	l.pos = i
+	t.Raw = l.p[start:l.pos]
+	if t.Final == 'M' || t.Final == 'N' {
+		return l.music(t)
+	}
+	return t
+}
+
+// music returns the token of an ANSI music sequence, if the control sequence is followed
+// by music commands and a shift out character. Otherwise the control sequence is returned,
+// as ESC [ M is also the delete line function.
+func (l *Lexer) music(t Token) Token {
+	if len(t.Params) > 0 || t.Private != 0 {
+		return t
+	}
+	start := l.pos - len(t.Raw)
+	end := min(len(l.p), l.pos+MaxMusic)
+	i := bytes.IndexByte(l.p[l.pos:end], SO)
+	if i < 0 {
+		return t
+	}
+	for _, c := range l.p[l.pos : l.pos+i] {
+		if !musical(c) {
+			return t
+		}
+	}
+	l.pos += i + 1
+	t.Kind = Music
+	t.Raw = l.p[start:l.pos]
+	return t
+}
+
+// musical returns true if the character is used by the ANSI music commands,
+// such as the notes A to G, the tempo T, the octave O and the length L.
+func musical(c byte) bool {
+	switch {
+	case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		return true
+	}
+	switch c {
+	case ' ', '#', '+', '-', '.', '<', '>':
+		return true
+	}
+	return false
+}
+
+// command returns the token of an operating system command,
+// which ends with a bell or an ESC \ string terminator.
+func (l *Lexer) command(start int) Token {
+	i := start + 2
+	for i < len(l.p) {
+		switch {
+		case l.p[i] == BEL:
+			i++
+		case l.p[i] == ESC && i+1 < len(l.p) && l.p[i+1] == '\\':
+			i += 2
+		case l.p[i] == ESC:
+			// an unterminated command
+		default:
+			i++
+			continue
+		}
+		break
+	}
+	l.pos = i
+	return Token{Kind: Command, Raw: l.p[start:l.pos], Final: ']'}
+}
+
+// parse returns the numeric parameters, where an omitted parameter is -1
+// and a large parameter is reduced to the MaxParam.
+func parse(p []byte) []int {
+	if len(p) == 0 {
+		return nil
+	}
+	fields := bytes.Split(p, []byte{';'})
+	params := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.Atoi(string(f))
+		switch {
+		case errors.Is(err, strconv.ErrRange):
+			n = MaxParam
+		case err != nil || n < 0:
+			n = -1
+		}
+		params = append(params, min(n, MaxParam))
+	}
+	return params
+}
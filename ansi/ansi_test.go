@@ -0,0 +1,109 @@
+package ansi_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/Defacto2/helper/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleStrip() {
+	art := "\x1b[1;33mHello\x1b[3Cworld\x1b[0m!\r\n"
+	fmt.Printf("%q\n", ansi.Strip([]byte(art)))
+	// Output:
+	// "Hello   world!\r\n"
+}
+
+func TestTokens(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, ansi.Tokens(nil))
+
+	tokens := ansi.Tokens([]byte("\x1b[0;1;33mHi\r\n\x1b[?7h\x1b[;5H\x1bcok"))
+	kinds := []ansi.Kind{}
+	for _, tok := range tokens {
+		kinds = append(kinds, tok.Kind)
+	}
+	assert.Equal(t, []ansi.Kind{
+		ansi.Sequence, ansi.Text, ansi.Control, ansi.Control,
+		ansi.Sequence, ansi.Sequence, ansi.Escape, ansi.Text,
+	}, kinds)
+
+	sgr := tokens[0]
+	assert.Equal(t, byte('m'), sgr.Final)
+	assert.Equal(t, []int{0, 1, 33}, sgr.Params)
+	assert.Equal(t, "\x1b[0;1;33m", string(sgr.Raw))
+
+	mode := tokens[4]
+	assert.Equal(t, byte('?'), mode.Private)
+	assert.Equal(t, []int{7}, mode.Params)
+
+	cup := tokens[5]
+	assert.Equal(t, []int{-1, 5}, cup.Params)
+	assert.Equal(t, 1, cup.Param(0, 1))
+	assert.Equal(t, 5, cup.Param(1, 1))
+	assert.Equal(t, 1, cup.Param(2, 1))
+
+	assert.Equal(t, byte('c'), tokens[6].Final)
+	assert.Equal(t, "sequence", ansi.Sequence.String())
+
+	huge := ansi.Tokens([]byte("\x1b[12345;9223372036854775807;99999999999999999999C"))
+	assert.Equal(t, []int{ansi.MaxParam, ansi.MaxParam, ansi.MaxParam}, huge[0].Params)
+}
+
+func TestAmiga(t *testing.T) {
+	t.Parallel()
+	// 0x9b is the Amiga CSI when followed by parameters, otherwise it is the CP-437 cent sign
+	tokens := ansi.Tokens([]byte("\x9b0;31mRed \x9b 5 cents"))
+	assert.Len(t, tokens, 2)
+	assert.Equal(t, ansi.Sequence, tokens[0].Kind)
+	assert.Equal(t, []int{0, 31}, tokens[0].Params)
+	assert.Equal(t, "Red \x9b 5 cents", string(tokens[1].Raw))
+}
+
+func TestMusic(t *testing.T) {
+	t.Parallel()
+	tokens := ansi.Tokens([]byte("\x1b[MFT120L8CDEFG\x0eDone\x1b[M"))
+	assert.Len(t, tokens, 3)
+	assert.Equal(t, ansi.Music, tokens[0].Kind)
+	assert.Equal(t, "Done", string(tokens[1].Raw))
+	// without a shift out, ESC [ M is the delete line sequence
+	assert.Equal(t, ansi.Sequence, tokens[2].Kind)
+	assert.Equal(t, byte('M'), tokens[2].Final)
+}
+
+func TestStrip(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, ansi.Strip(nil))
+	tests := []struct {
+		name   string
+		art    string
+		expect string
+	}{
+		{"plain", "Hello world", "Hello world"},
+		{"colors", "\x1b[1;31mRed\x1b[0m \x1b[32mGreen", "Red Green"},
+		{"forward", "A\x1b[CB\x1b[2CC", "A B  C"},
+		{"bell", "Ring\x07 ring\x08", "Ring ring"},
+		{"music", "\x1b[MFT120CDE\x0ePlay", "Play"},
+		{"command", "\x1b]0;title\x07Text", "Text"},
+		{"incomplete", "Text\x1b[1;3", "Text"},
+		{"amiga", "\x9b1;32mTopaz", "Topaz"},
+		{"glyphs", "\x03\x04 cards", "\x03\x04 cards"},
+		{"lines", "one\r\ntwo\n", "one\r\ntwo\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expect, string(ansi.Strip([]byte(tt.art))))
+		})
+	}
+}
+
+func TestWriteStrip(t *testing.T) {
+	t.Parallel()
+	buf := new(bytes.Buffer)
+	err := ansi.WriteStrip(buf, []byte("\x1b[5C*"))
+	assert.NoError(t, err)
+	assert.Equal(t, "     *", buf.String())
+}
@@ -0,0 +1,46 @@
+package ansi
+
+import (
+	_ "embed"
+)
+
+var (
+	//go:embed font/vga.f16
+	vga []byte
+	//go:embed font/topaz.f08
+	topaz []byte
+)
+
+// FontWidth is the width in pixels of the glyphs of a font.
+const FontWidth = 8
+
+// Font is a bitmap font of 256 glyphs.
+type Font struct {
+	Name   string // Name is the name of the font using the SAUCE font naming.
+	Height int    // Height is the height in pixels of the glyphs.
+	// glyphs are the rows of the glyphs in character order, Height bytes for each glyph,
+	// where the most significant bit of a row is the left pixel.
+	glyphs []byte
+}
+
+// VGA is a recreation of the IBM VGA 8x16 font of the CP-437 character set.
+var VGA = &Font{Name: "IBM VGA", Height: 16, glyphs: vga}
+
+// Topaz is a recreation of the Amiga Topaz 8x8 font of the ISO-8859-1 character set.
+// The rows are doubled to match the tall pixels of the Amiga high resolution screen.
+var Topaz = &Font{Name: "Amiga Topaz 2", Height: 16, glyphs: double(topaz)}
+
+// Glyph returns the rows of the glyph of the character.
+func (f *Font) Glyph(char byte) []byte {
+	i := int(char) * f.Height
+	return f.glyphs[i : i+f.Height]
+}
+
+// double returns the glyphs with each row repeated.
+func double(glyphs []byte) []byte {
+	p := make([]byte, 0, 2*len(glyphs))
+	for _, row := range glyphs {
+		p = append(p, row, row)
+	}
+	return p
+}
@@ -0,0 +1,210 @@
+package ansi
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/Defacto2/helper/codepage"
+)
+
+// Class is the default class name of the HTML pre element.
+const Class = "ansi"
+
+// Palette is the 16 color palette of the IBM PC VGA text mode.
+var Palette = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xff}, {0x00, 0x00, 0xaa, 0xff}, {0x00, 0xaa, 0x00, 0xff}, {0x00, 0xaa, 0xaa, 0xff},
+	{0xaa, 0x00, 0x00, 0xff}, {0xaa, 0x00, 0xaa, 0xff}, {0xaa, 0x55, 0x00, 0xff}, {0xaa, 0xaa, 0xaa, 0xff},
+	{0x55, 0x55, 0x55, 0xff}, {0x55, 0x55, 0xff, 0xff}, {0x55, 0xff, 0x55, 0xff}, {0x55, 0xff, 0xff, 0xff},
+	{0xff, 0x55, 0x55, 0xff}, {0xff, 0x55, 0xff, 0xff}, {0xff, 0xff, 0x55, 0xff}, {0xff, 0xff, 0xff, 0xff},
+}
+
+// Glyph returns the glyph of the CP-437 character code,
+// including the symbols of the control characters.
+func Glyph(char byte) rune {
+	if char == 0x00 {
+		return ' '
+	}
+	return codepage.CodePage437(0).DecodeByte(char)
+}
+
+// blinkKeyframes are the keyframes of the blink animation in the inline style, which as a constant name
+// cannot be used to inject markup using the class name.
+const blinkKeyframes = "@keyframes " + Class + "-blink{50%{color:transparent}}"
+
+// hex returns the color as a CSS hexadecimal value.
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// HTMLOptions are the options of the HTML renderer.
+type HTMLOptions struct {
+	Class  string // Class is the class name of the pre element, or Class when empty or not a CSS identifier.
+	Inline bool   // Inline uses style attributes for the colors instead of class names.
+}
+
+// className returns the class name when it is a CSS identifier of letters, digits, hyphens and underscores
+// that does not begin with a digit, otherwise it returns Class.
+func className(class string) string {
+	if class == "" || (class[0] >= '0' && class[0] <= '9') {
+		return Class
+	}
+	for _, char := range []byte(class) {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char >= '0' && char <= '9',
+			char == '-', char == '_':
+		default:
+			return Class
+		}
+	}
+	return class
+}
+
+// CSS returns the stylesheet for the class names of the HTML renderer,
+// scoped to the class name of the pre element, or Class when empty or not a CSS identifier.
+func CSS(class string) string {
+	class = className(class)
+	var b strings.Builder
+	fmt.Fprintf(&b, ".%s{color:%s;background-color:%s}\n", class, hex(Palette[LightGray]), hex(Palette[Black]))
+	for i, c := range Palette {
+		fmt.Fprintf(&b, ".%s .f%d{color:%s}\n", class, i, hex(c))
+	}
+	for i, c := range Palette {
+		fmt.Fprintf(&b, ".%s .b%d{background-color:%s}\n", class, i, hex(c))
+	}
+	fmt.Fprintf(&b, ".%s .blink{animation:%s-blink 1s step-end infinite}\n", class, class)
+	fmt.Fprintf(&b, "@keyframes %s-blink{50%%{color:transparent}}\n", class)
+	return b.String()
+}
+
+// WriteHTML writes the screen to w as a HTML pre element.
+// Each run of cells with the same colors is a span element, except for the
+// default light gray on black, and the characters are the CP-437 glyphs.
+// Trailing spaces of the rows are removed.
+// As a style attribute cannot hold keyframes, the inline mode writes a style element
+// before the pre element for the blink animation when any cells blink.
+func (s *Screen) WriteHTML(w io.Writer, opts HTMLOptions) error {
+	class := className(opts.Class)
+	b := bufio.NewWriter(w)
+	if opts.Inline && s.blinks() {
+		b.WriteString("<style>" + blinkKeyframes + "</style>\n")
+	}
+	if opts.Inline {
+		fmt.Fprintf(b, `<pre class="%s" style="color:%s;background-color:%s">`,
+			class, hex(Palette[LightGray]), hex(Palette[Black]))
+	} else {
+		fmt.Fprintf(b, `<pre class="%s">`, class)
+	}
+	for y := range s.Rows() {
+		if y > 0 {
+			b.WriteByte('\n')
+		}
+		row := trimRow(s.Row(y))
+		for x := 0; x < len(row); {
+			n := x + 1
+			for n < len(row) && sameColors(row[x], row[n]) {
+				n++
+			}
+			span(b, row[x:n], opts.Inline)
+			x = n
+		}
+	}
+	b.WriteString("</pre>\n")
+	if err := b.Flush(); err != nil {
+		return fmt.Errorf("write html %w", err)
+	}
+	return nil
+}
+
+// blinks returns true if any of the cells blink.
+func (s *Screen) blinks() bool {
+	for y := range s.Rows() {
+		for _, c := range s.Row(y) {
+			if c.Blink {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HTML returns the ANSI text of p as a HTML pre element.
+func HTML(p []byte, opts Options, style HTMLOptions) ([]byte, error) {
+	var b bytes.Buffer
+	if err := Render(p, opts).WriteHTML(&b, style); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// trimRow returns the row without the trailing spaces that use the default colors.
+func trimRow(row []Cell) []Cell {
+	for len(row) > 0 && row[len(row)-1] == blank {
+		row = row[:len(row)-1]
+	}
+	return row
+}
+
+// sameColors returns true if the cells use the same colors.
+// The foreground of a space is ignored.
+func sameColors(a, b Cell) bool {
+	if a.Back != b.Back {
+		return false
+	}
+	if b.Char == ' ' && !b.Blink {
+		return true
+	}
+	return a.Fore == b.Fore && a.Blink == b.Blink
+}
+
+// span writes the cells as a span element with the colors of the first cell.
+func span(b *bufio.Writer, cells []Cell, inline bool) {
+	c := cells[0]
+	plain := c.Fore == LightGray && c.Back == Black && !c.Blink
+	if !plain {
+		switch {
+		case inline:
+			b.WriteString(`<span style="`)
+			if c.Fore != LightGray {
+				fmt.Fprintf(b, "color:%s;", hex(Palette[c.Fore]))
+			}
+			if c.Back != Black {
+				fmt.Fprintf(b, "background-color:%s;", hex(Palette[c.Back]))
+			}
+			if c.Blink {
+				b.WriteString("animation:" + Class + "-blink 1s step-end infinite;")
+			}
+			b.WriteString(`">`)
+		default:
+			classes := []string{}
+			if c.Fore != LightGray {
+				classes = append(classes, fmt.Sprintf("f%d", c.Fore))
+			}
+			if c.Back != Black {
+				classes = append(classes, fmt.Sprintf("b%d", c.Back))
+			}
+			if c.Blink {
+				classes = append(classes, "blink")
+			}
+			fmt.Fprintf(b, `<span class="%s">`, strings.Join(classes, " "))
+		}
+	}
+	for _, cell := range cells {
+		switch r := Glyph(cell.Char); r {
+		case '<':
+			b.WriteString("&lt;")
+		case '>':
+			b.WriteString("&gt;")
+		case '&':
+			b.WriteString("&amp;")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if !plain {
+		b.WriteString("</span>")
+	}
+}
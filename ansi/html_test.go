@@ -0,0 +1,108 @@
+package ansi_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Defacto2/helper/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func ExampleHTML() {
+	art := "\x1b[1;33mHi\x1b[0m \x03 <3\r\n"
+	p, _ := ansi.HTML([]byte(art), ansi.Options{}, ansi.HTMLOptions{})
+	fmt.Print(string(p))
+	// Output:
+	// <pre class="ansi"><span class="f14">Hi </span>♥ &lt;3</pre>
+}
+
+func TestGlyph(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, ' ', ansi.Glyph(0x00))
+	assert.Equal(t, '☺', ansi.Glyph(0x01))
+	assert.Equal(t, '▼', ansi.Glyph(0x1f))
+	assert.Equal(t, 'A', ansi.Glyph('A'))
+	assert.Equal(t, '⌂', ansi.Glyph(0x7f))
+	assert.Equal(t, 'Ç', ansi.Glyph(0x80))
+	assert.Equal(t, '█', ansi.Glyph(0xdb))
+	assert.Equal(t, ' ', ansi.Glyph(0xff))
+}
+
+func TestCSS(t *testing.T) {
+	t.Parallel()
+	css := ansi.CSS("")
+	assert.Contains(t, css, ".ansi .f12{color:#ff5555}\n")
+	assert.Contains(t, css, ".ansi .b6{background-color:#aa5500}\n")
+	assert.Contains(t, ansi.CSS("art"), ".art .blink{")
+	assert.Contains(t, ansi.CSS("_my-art2"), "._my-art2 .blink{")
+
+	// a class name that is not a CSS identifier cannot break out of the style element
+	for _, class := range []string{"x{}</style><script>alert(1)</script>", "2art", "my art", "art.big"} {
+		css := ansi.CSS(class)
+		assert.Equal(t, ansi.CSS(""), css, class)
+		var b strings.Builder
+		require.NoError(t, ansi.Render([]byte("Hi"), ansi.Options{}).WriteHTML(&b, ansi.HTMLOptions{Class: class}))
+		assert.Equal(t, "<pre class=\"ansi\">Hi</pre>\n", b.String(), class)
+	}
+}
+
+func TestWriteHTMLGolden(t *testing.T) {
+	t.Parallel()
+	art, err := os.ReadFile(filepath.Join("testdata", "DEMO.ANS"))
+	require.NoError(t, err)
+	tests := []struct {
+		golden string
+		opts   ansi.Options
+		style  ansi.HTMLOptions
+	}{
+		{"DEMO.html", ansi.Options{}, ansi.HTMLOptions{}},
+		{"DEMO.ice.html", ansi.Options{ICEColors: true}, ansi.HTMLOptions{}},
+		{"DEMO.inline.html", ansi.Options{}, ansi.HTMLOptions{Inline: true, Class: "art"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			t.Parallel()
+			p, err := ansi.HTML(art, tt.opts, tt.style)
+			require.NoError(t, err)
+			name := filepath.Join("testdata", tt.golden)
+			if *update {
+				require.NoError(t, os.WriteFile(name, p, 0o644))
+			}
+			want, err := os.ReadFile(name)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(p))
+		})
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	t.Parallel()
+	var b bytes.Buffer
+	err := ansi.NewScreen(ansi.Options{}).WriteHTML(&b, ansi.HTMLOptions{Class: `a"b`})
+	require.NoError(t, err)
+	assert.Equal(t, "<pre class=\"ansi\"></pre>\n", b.String(), "a class that is not a CSS identifier")
+
+	// a space with a different foreground color does not split a span
+	p, err := ansi.HTML([]byte("\x1b[31;44ma\x1b[32m b\x1b[0m   "), ansi.Options{}, ansi.HTMLOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "<pre class=\"ansi\"><span class=\"f4 b1\">a </span><span class=\"f2 b1\">b</span></pre>\n", string(p))
+	assert.False(t, strings.Contains(string(p), "   "))
+
+	// the inline mode animates the blinking cells with its own keyframes
+	p, err = ansi.HTML([]byte("\x1b[5ma"), ansi.Options{}, ansi.HTMLOptions{Inline: true, Class: "</style>"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(p), "<style>@keyframes ansi-blink{"))
+	assert.Contains(t, string(p), `<span style="animation:ansi-blink 1s step-end infinite;">a</span>`)
+	assert.Equal(t, 1, strings.Count(string(p), "</style>"))
+	p, err = ansi.HTML([]byte("a"), ansi.Options{}, ansi.HTMLOptions{Inline: true})
+	require.NoError(t, err)
+	assert.NotContains(t, string(p), "<style>")
+}
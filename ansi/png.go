@@ -0,0 +1,156 @@
+package ansi
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+)
+
+// MaxPixels is the default maximum number of pixels of an image, which is 128 MB of RGBA.
+const MaxPixels = 1 << 25
+
+// ImageOptions are the options of the image renderer.
+type ImageOptions struct {
+	Font      *Font   // Font is the bitmap font, or VGA when nil.
+	NineDots  bool    // NineDots draws the 9 pixel wide characters of the VGA text mode.
+	Scale     float64 // Scale is the size of the image, such as 0.5 for half size, or 1 when zero or not finite.
+	MaxHeight int     // MaxHeight crops the bottom of the scaled image to a height in pixels, or no crop when zero.
+	MaxPixels int     // MaxPixels crops the bottom rows of a larger image, or MaxPixels when zero.
+}
+
+// Image returns the screen drawn as an image.
+// Blinking characters are drawn in their visible state.
+// The rows that would make the drawn or scaled image larger than the MaxPixels are not drawn,
+// so a small text of many rows cannot exhaust the memory.
+// The scale is reduced when a single scaled row is larger than the MaxPixels,
+// and an empty image is returned when a single row is larger at its actual size.
+func (s *Screen) Image(opts ImageOptions) *image.RGBA {
+	font := opts.Font
+	if font == nil {
+		font = VGA
+	}
+	width := FontWidth
+	if opts.NineDots {
+		width++
+	}
+	scale := opts.Scale
+	if scale <= 0 || math.IsNaN(scale) || math.IsInf(scale, 0) {
+		scale = 1
+	}
+	cols, rows := max(1, s.Columns()), max(1, s.Rows())
+	limit := opts.MaxPixels
+	if limit <= 0 {
+		limit = MaxPixels
+	}
+	rowPixels := float64(cols * width * font.Height)
+	if rowPixels > float64(limit) {
+		return image.NewRGBA(image.Rectangle{})
+	}
+	if rowPixels*scale*scale > float64(limit) {
+		// the resize rounds the width and height by up to half a pixel
+		scale = math.Sqrt(float64(limit)/rowPixels) - 0.5/float64(min(cols*width, font.Height))
+	}
+	rows = min(rows, max(1, int(float64(limit)/(rowPixels*max(1, scale*scale)))))
+	crop := 0
+	if opts.MaxHeight > 0 {
+		// only draw the rows that are visible after the crop
+		crop = int(math.Ceil(float64(opts.MaxHeight) / scale))
+		rows = min(rows, (crop+font.Height-1)/font.Height)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, cols*width, rows*font.Height))
+	for y := range rows {
+		for x := range cols {
+			draw(img, s.Cell(x, y), x*width, y*font.Height, font, opts.NineDots)
+		}
+	}
+	if crop > 0 && crop < img.Rect.Dy() {
+		img = img.SubImage(image.Rect(0, 0, img.Rect.Dx(), crop)).(*image.RGBA)
+	}
+	if scale != 1 {
+		img = resize(img, scale)
+	}
+	if opts.MaxHeight > 0 && img.Rect.Dy() > opts.MaxHeight {
+		img = img.SubImage(image.Rect(0, 0, img.Rect.Dx(), opts.MaxHeight)).(*image.RGBA)
+	}
+	return img
+}
+
+// draw draws the cell with its top left pixel at x and y.
+func draw(img *image.RGBA, c Cell, x, y int, font *Font, nine bool) {
+	const lineStart, lineEnd = 0xc0, 0xdf
+	fore, back := Palette[c.Fore], Palette[c.Back]
+	for r, bits := range font.Glyph(c.Char) {
+		for b := range FontWidth {
+			pixel := back
+			if bits&(0x80>>b) != 0 {
+				pixel = fore
+			}
+			img.SetRGBA(x+b, y+r, pixel)
+		}
+		if !nine {
+			continue
+		}
+		// the VGA repeats the last column of the line drawing characters
+		pixel := back
+		if c.Char >= lineStart && c.Char <= lineEnd && bits&1 != 0 {
+			pixel = fore
+		}
+		img.SetRGBA(x+FontWidth, y+r, pixel)
+	}
+}
+
+// resize returns the image scaled by the scale factor.
+// A pixel of a smaller image is the average of the pixels it covers.
+func resize(src *image.RGBA, scale float64) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	dw := max(1, int(math.Round(float64(sw)*scale)))
+	dh := max(1, int(math.Round(float64(sh)*scale)))
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	span := func(d, dn, sn int) (int, int) {
+		s0 := d * sn / dn
+		s1 := max(s0+1, (d+1)*sn/dn)
+		return s0, s1
+	}
+	for dy := range dh {
+		y0, y1 := span(dy, dh, sh)
+		for dx := range dw {
+			x0, x1 := span(dx, dw, sw)
+			var sum [4]int
+			for y := y0; y < y1; y++ {
+				i := src.PixOffset(src.Rect.Min.X+x0, src.Rect.Min.Y+y)
+				for x := x0; x < x1; x++ {
+					for c := range sum {
+						sum[c] += int(src.Pix[i+c])
+					}
+					i += 4
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := dst.PixOffset(dx, dy)
+			for c := range sum {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// WritePNG writes the screen to w as a PNG image.
+func (s *Screen) WritePNG(w io.Writer, opts ImageOptions) error {
+	if err := png.Encode(w, s.Image(opts)); err != nil {
+		return fmt.Errorf("write png %w", err)
+	}
+	return nil
+}
+
+// PNG returns the ANSI text of p as a PNG image.
+func PNG(p []byte, opts Options, img ImageOptions) ([]byte, error) {
+	var b bytes.Buffer
+	if err := Render(p, opts).WritePNG(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
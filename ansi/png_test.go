@@ -0,0 +1,134 @@
+package ansi_test
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Defacto2/helper/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFont(t *testing.T) {
+	t.Parallel()
+	for _, f := range []*ansi.Font{ansi.VGA, ansi.Topaz} {
+		assert.Equal(t, 16, f.Height, f.Name)
+		assert.Equal(t, make([]byte, 16), f.Glyph(' '), f.Name)
+		assert.NotEqual(t, make([]byte, 16), f.Glyph('A'), f.Name)
+		assert.Len(t, f.Glyph(0xff), 16, f.Name)
+	}
+	assert.Equal(t, []byte{0xff, 0xff}, ansi.VGA.Glyph(0xdb)[:2])
+	assert.Equal(t, byte(0x18), ansi.VGA.Glyph(0xb3)[0])
+	// the Topaz rows are doubled
+	a := ansi.Topaz.Glyph('A')
+	assert.Equal(t, a[0], a[1])
+	assert.Equal(t, a[2], a[3])
+}
+
+func TestImage(t *testing.T) {
+	t.Parallel()
+	// an empty screen is a single blank cell
+	img := ansi.NewScreen(ansi.Options{}).Image(ansi.ImageOptions{})
+	assert.Equal(t, 8, img.Rect.Dx())
+	assert.Equal(t, 16, img.Rect.Dy())
+
+	s := ansi.Render([]byte("\x1b[1;33;44m\xdb\x1b[0m \xc4\r\nab"), ansi.Options{})
+	img = s.Image(ansi.ImageOptions{})
+	assert.Equal(t, 3*8, img.Rect.Dx())
+	assert.Equal(t, 2*16, img.Rect.Dy())
+	assert.Equal(t, ansi.Palette[ansi.Yellow], img.RGBAAt(0, 0))
+	assert.Equal(t, ansi.Palette[ansi.Black], img.RGBAAt(8, 0))
+	assert.Equal(t, ansi.Palette[ansi.LightGray], img.RGBAAt(23, 7))
+
+	img = s.Image(ansi.ImageOptions{NineDots: true})
+	assert.Equal(t, 3*9, img.Rect.Dx())
+	// the line drawing characters extend into the ninth column
+	assert.Equal(t, ansi.Palette[ansi.LightGray], img.RGBAAt(26, 7))
+	assert.Equal(t, ansi.Palette[ansi.Yellow], img.RGBAAt(8, 7))
+	assert.Equal(t, ansi.Palette[ansi.Black], img.RGBAAt(17, 7))
+
+	img = s.Image(ansi.ImageOptions{Font: ansi.Topaz})
+	assert.Equal(t, 3*8, img.Rect.Dx())
+	assert.Equal(t, 2*16, img.Rect.Dy())
+}
+
+func TestImageScale(t *testing.T) {
+	t.Parallel()
+	s := ansi.Render(bytes.Repeat([]byte("\xdb\xdb\r\n"), 10), ansi.Options{})
+	img := s.Image(ansi.ImageOptions{Scale: 2})
+	assert.Equal(t, 32, img.Rect.Dx())
+	assert.Equal(t, 320, img.Rect.Dy())
+
+	img = s.Image(ansi.ImageOptions{Scale: 0.5})
+	assert.Equal(t, 8, img.Rect.Dx())
+	assert.Equal(t, 80, img.Rect.Dy())
+	assert.Equal(t, ansi.Palette[ansi.LightGray], img.RGBAAt(4, 40))
+
+	// the crop uses the height of the scaled image
+	img = s.Image(ansi.ImageOptions{Scale: 0.5, MaxHeight: 50})
+	assert.Equal(t, 8, img.Rect.Dx())
+	assert.Equal(t, 50, img.Rect.Dy())
+	img = s.Image(ansi.ImageOptions{MaxHeight: 1000})
+	assert.Equal(t, 160, img.Rect.Dy())
+
+	// a half tone block averages to a mid gray
+	img = ansi.Render([]byte("\xb1"), ansi.Options{}).Image(ansi.ImageOptions{Scale: 0.125})
+	assert.Equal(t, 1, img.Rect.Dx())
+	assert.Equal(t, 2, img.Rect.Dy())
+	assert.Equal(t, uint8(0x55), img.RGBAAt(0, 0).R)
+}
+
+func TestImageMaxPixels(t *testing.T) {
+	t.Parallel()
+	// each row of 80 columns is 80x8x16 pixels
+	const rowPixels = 80 * 8 * 16
+	s := ansi.Render(bytes.Repeat(append(bytes.Repeat([]byte{0xdb}, 80), "\r\n"...), 1000), ansi.Options{})
+	require.Equal(t, 1000, s.Rows())
+	img := s.Image(ansi.ImageOptions{MaxPixels: 10 * rowPixels})
+	assert.Equal(t, 640, img.Rect.Dx())
+	assert.Equal(t, 10*16, img.Rect.Dy())
+
+	// the scaled image is also limited
+	img = s.Image(ansi.ImageOptions{MaxPixels: 10 * rowPixels, Scale: 2})
+	assert.Equal(t, 2*640, img.Rect.Dx())
+	assert.Equal(t, 2*2*16, img.Rect.Dy())
+
+	// a single row larger than the limit is not drawn
+	img = s.Image(ansi.ImageOptions{MaxPixels: 1})
+	assert.True(t, img.Rect.Empty())
+
+	// the scale of a single row larger than the limit is reduced
+	for _, scale := range []float64{1e6, 1e154} {
+		img = s.Image(ansi.ImageOptions{MaxPixels: 10 * rowPixels, Scale: scale})
+		assert.Greater(t, img.Rect.Dx(), 640, scale)
+		assert.LessOrEqual(t, img.Rect.Dx()*img.Rect.Dy(), 10*rowPixels, scale)
+	}
+
+	// a scale that is not a number is the actual size
+	for _, scale := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		img = s.Image(ansi.ImageOptions{MaxPixels: 10 * rowPixels, Scale: scale})
+		assert.Equal(t, 640, img.Rect.Dx(), scale)
+		assert.Equal(t, 10*16, img.Rect.Dy(), scale)
+	}
+
+	// the default limit is larger than this text
+	img = s.Image(ansi.ImageOptions{})
+	assert.Equal(t, 1000*16, img.Rect.Dy())
+	assert.LessOrEqual(t, img.Rect.Dx()*img.Rect.Dy(), ansi.MaxPixels)
+}
+
+func TestPNG(t *testing.T) {
+	t.Parallel()
+	art, err := os.ReadFile(filepath.Join("testdata", "DEMO.ANS"))
+	require.NoError(t, err)
+	p, err := ansi.PNG(art, ansi.Options{}, ansi.ImageOptions{MaxHeight: 40})
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(p))
+	require.NoError(t, err)
+	assert.Equal(t, 31*8, img.Bounds().Dx())
+	assert.Equal(t, 40, img.Bounds().Dy())
+}
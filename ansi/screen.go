@@ -0,0 +1,413 @@
+package ansi
+
+// Width is the default number of columns of a screen.
+const Width = 80
+
+// MaxRows is the default maximum number of rows of a screen.
+const MaxRows = 10000
+
+// The 16 colors of the IBM PC text mode palette.
+const (
+	Black uint8 = iota
+	Blue
+	Green
+	Cyan
+	Red
+	Magenta
+	Brown
+	LightGray
+	DarkGray
+	LightBlue
+	LightGreen
+	LightCyan
+	LightRed
+	LightMagenta
+	Yellow
+	White
+)
+
+// ansiColors maps the ANSI color order of the SGR parameters to the IBM PC palette.
+var ansiColors = [8]uint8{Black, Red, Green, Brown, Blue, Magenta, Cyan, LightGray}
+
+// Cell is a character cell of a screen.
+type Cell struct {
+	Char  byte  // Char is the character code in the code page of the text, such as CP-437.
+	Fore  uint8 // Fore is the foreground color, 0 to 15 of the IBM PC palette.
+	Back  uint8 // Back is the background color, 0 to 7, or 0 to 15 when using iCE colors.
+	Blink bool  // Blink is true if the foreground blinks.
+}
+
+// blank is an empty cell.
+var blank = Cell{Char: ' ', Fore: LightGray, Back: Black}
+
+// Options are the options of a screen.
+type Options struct {
+	Width     int  // Width is the number of columns, or Width when zero.
+	MaxRows   int  // MaxRows is the maximum number of rows, or MaxRows when zero.
+	ICEColors bool // ICEColors uses the blink attribute for bright background colors.
+	NoWrap    bool // NoWrap disables the automatic wrap of text at the last column.
+}
+
+// attributes are the graphic rendition attributes of the cursor.
+type attributes struct {
+	fore, back                 uint8
+	bold, blink, inverse, hide bool
+}
+
+// Screen is a virtual ANSI.SYS compatible terminal that renders an ANSI text into a grid of cells.
+// Unlike a terminal, the screen never scrolls but grows downwards up to its maximum rows.
+type Screen struct {
+	width   int
+	maxRows int
+	ice     bool
+	wrap    bool
+	rows    [][]Cell
+	x, y    int
+	sx, sy  int // sx and sy are the saved cursor position
+	attr    attributes
+	columns int  // columns is the number of columns used
+	end     bool // end is true after the end-of-file marker
+}
+
+// NewScreen returns an empty screen.
+func NewScreen(opts Options) *Screen {
+	s := &Screen{
+		width:   opts.Width,
+		maxRows: opts.MaxRows,
+		ice:     opts.ICEColors,
+		wrap:    !opts.NoWrap,
+	}
+	if s.width <= 0 {
+		s.width = Width
+	}
+	if s.maxRows <= 0 {
+		s.maxRows = MaxRows
+	}
+	s.reset()
+	return s
+}
+
+// Render returns a screen with the ANSI text of p rendered to it.
+func Render(p []byte, opts Options) *Screen {
+	s := NewScreen(opts)
+	s.Render(p)
+	return s
+}
+
+// Render renders the ANSI text of p to the screen, continuing from the cursor position.
+// The rendering stops at the MS-DOS end-of-file marker, which precedes any SAUCE metadata.
+func (s *Screen) Render(p []byte) {
+	l := NewLexer(p)
+	for !s.end {
+		t, ok := l.Next()
+		if !ok {
+			return
+		}
+		switch t.Kind {
+		case Text:
+			s.text(t.Raw)
+		case Control:
+			s.control(t.Final)
+		case Sequence:
+			s.sequence(t)
+		case Escape:
+			if t.Final == 'c' {
+				s.clear()
+				s.reset()
+			}
+		case Command, Music:
+		}
+	}
+}
+
+// Width returns the number of columns of the screen.
+func (s *Screen) Width() int {
+	return s.width
+}
+
+// Columns returns the number of columns that were used by the text,
+// which is the true width of the ANSI art.
+func (s *Screen) Columns() int {
+	return s.columns
+}
+
+// Rows returns the number of rows that were used by the text.
+func (s *Screen) Rows() int {
+	return len(s.rows)
+}
+
+// Cursor returns the column and row of the cursor.
+func (s *Screen) Cursor() (int, int) {
+	return s.x, s.y
+}
+
+// Row returns the cells of the row y, or nil if the row was not used.
+// The returned cells share the memory of the screen.
+func (s *Screen) Row(y int) []Cell {
+	if y < 0 || y >= len(s.rows) {
+		return nil
+	}
+	return s.rows[y]
+}
+
+// Cell returns the cell at the column x and row y.
+func (s *Screen) Cell(x, y int) Cell {
+	if row := s.Row(y); x >= 0 && x < len(row) {
+		return row[x]
+	}
+	return blank
+}
+
+// ICEColors returns true if the screen uses iCE colors.
+func (s *Screen) ICEColors() bool {
+	return s.ice
+}
+
+// reset sets the graphic rendition attributes to their defaults.
+func (s *Screen) reset() {
+	s.attr = attributes{fore: LightGray}
+}
+
+// clear erases the screen and moves the cursor to the top left.
+func (s *Screen) clear() {
+	s.rows = nil
+	s.columns = 0
+	s.x, s.y = 0, 0
+}
+
+// row returns the row y, adding any missing rows, or nil if it exceeds the maximum rows.
+func (s *Screen) row(y int) []Cell {
+	if y >= s.maxRows {
+		return nil
+	}
+	for len(s.rows) <= y {
+		r := make([]Cell, s.width)
+		for i := range r {
+			r[i] = blank
+		}
+		s.rows = append(s.rows, r)
+	}
+	return s.rows[y]
+}
+
+// cell returns the cell of the current attributes.
+func (s *Screen) cell(char byte) Cell {
+	a := s.attr
+	fore, back := a.fore, a.back
+	if a.inverse {
+		fore, back = back, fore
+	}
+	if a.bold {
+		fore |= 8
+	}
+	c := Cell{Char: char, Fore: fore, Back: back &^ 8, Blink: a.blink}
+	if s.ice {
+		c.Back = back
+		if a.blink {
+			c.Back |= 8
+			c.Blink = false
+		}
+	}
+	if a.hide {
+		c.Fore = c.Back
+	}
+	return c
+}
+
+// text writes the characters at the cursor.
+func (s *Screen) text(p []byte) {
+	for _, char := range p {
+		if char == SUB {
+			s.end = true
+			return
+		}
+		if s.x >= s.width {
+			if !s.wrap {
+				continue
+			}
+			s.x = 0
+			s.y++
+		}
+		row := s.row(s.y)
+		if row == nil {
+			s.end = true
+			return
+		}
+		row[s.x] = s.cell(char)
+		s.x++
+		s.columns = max(s.columns, s.x)
+	}
+}
+
+// control applies the control character.
+func (s *Screen) control(char byte) {
+	const tab = 8
+	switch char {
+	case CR:
+		s.x = 0
+	case LF:
+		s.x = 0
+		s.y++
+		s.row(s.y - 1)
+	case BS:
+		s.x = max(0, s.x-1)
+	case HT:
+		s.x = min(s.width-1, (s.x/tab+1)*tab)
+	case FF:
+		s.clear()
+	}
+}
+
+// sequence applies the control sequence.
+func (s *Screen) sequence(t Token) {
+	switch t.Final {
+	case 'A':
+		s.y = max(0, s.y-t.Param(0, 1))
+	case 'B':
+		s.y = min(s.maxRows-1, s.y+t.Param(0, 1))
+	case 'C':
+		s.x = min(s.width-1, s.x+t.Param(0, 1))
+	case 'D':
+		s.x = max(0, min(s.x, s.width-1)-t.Param(0, 1))
+	case 'H', 'f':
+		s.y = min(s.maxRows-1, t.Param(0, 1)-1)
+		s.x = min(s.width-1, t.Param(1, 1)-1)
+	case 'J':
+		s.erase(t.Param(0, 0))
+	case 'K':
+		s.eraseLine(t.Param(0, 0))
+	case 'm':
+		s.graphics(t.Params)
+	case 's':
+		s.sx, s.sy = s.x, s.y
+	case 'u':
+		s.x, s.y = s.sx, s.sy
+	case 'h', 'l':
+		s.mode(t)
+	}
+}
+
+// mode sets or resets the line wrap and iCE color modes.
+func (s *Screen) mode(t Token) {
+	const wrap, ice = 7, 33
+	set := t.Final == 'h'
+	switch {
+	case t.Param(0, 0) == wrap && (t.Private == '?' || t.Private == '='):
+		s.wrap = set
+	case t.Param(0, 0) == ice && t.Private == '?':
+		s.ice = set
+	}
+}
+
+// erase erases part or all of the screen.
+func (s *Screen) erase(n int) {
+	const below, above, all = 0, 1, 2
+	switch n {
+	case below:
+		s.eraseLine(below)
+		for y := s.y + 1; y < len(s.rows); y++ {
+			s.fill(y, 0, s.width)
+		}
+	case above:
+		s.eraseLine(above)
+		for y := 0; y < s.y && y < len(s.rows); y++ {
+			s.fill(y, 0, s.width)
+		}
+	case all:
+		// ANSI.SYS also moves the cursor to the top left
+		s.clear()
+	}
+}
+
+// eraseLine erases part or all of the cursor line.
+func (s *Screen) eraseLine(n int) {
+	const right, left, all = 0, 1, 2
+	x := min(s.x, s.width-1)
+	switch n {
+	case right:
+		s.fill(s.y, x, s.width)
+	case left:
+		s.fill(s.y, 0, x+1)
+	case all:
+		s.fill(s.y, 0, s.width)
+	}
+}
+
+// fill erases the cells of row y from column x1 up to x2 using the background color.
+func (s *Screen) fill(y, x1, x2 int) {
+	if y >= len(s.rows) {
+		return
+	}
+	c := blank
+	c.Back = s.cell(' ').Back
+	row := s.rows[y]
+	for x := x1; x < x2; x++ {
+		row[x] = c
+	}
+}
+
+// graphics applies the select graphic rendition parameters.
+func (s *Screen) graphics(params []int) {
+	if len(params) == 0 {
+		s.reset()
+		return
+	}
+	const (
+		reset      = 0
+		bold       = 1
+		faint      = 2
+		blink      = 5
+		rapidBlink = 6
+		inverse    = 7
+		hide       = 8
+		normal     = 22
+		steady     = 25
+		positive   = 27
+		reveal     = 28
+		foreStart  = 30
+		foreEnd    = 37
+		foreReset  = 39
+		backStart  = 40
+		backEnd    = 47
+		backReset  = 49
+		brightFore = 90
+		brightEnd  = 97
+		brightBack = 100
+		brightBEnd = 107
+	)
+	a := &s.attr
+	for _, p := range params {
+		switch {
+		case p <= reset:
+			s.reset()
+		case p == bold:
+			a.bold = true
+		case p == faint, p == normal:
+			a.bold = false
+		case p == blink, p == rapidBlink:
+			a.blink = true
+		case p == inverse:
+			a.inverse = true
+		case p == hide:
+			a.hide = true
+		case p == steady:
+			a.blink = false
+		case p == positive:
+			a.inverse = false
+		case p == reveal:
+			a.hide = false
+		case p >= foreStart && p <= foreEnd:
+			a.fore = ansiColors[p-foreStart]
+		case p == foreReset:
+			a.fore = LightGray
+		case p >= backStart && p <= backEnd:
+			a.back = ansiColors[p-backStart]
+		case p == backReset:
+			a.back = Black
+		case p >= brightFore && p <= brightEnd:
+			a.fore = ansiColors[p-brightFore] | 8
+		case p >= brightBack && p <= brightBEnd:
+			a.back = ansiColors[p-brightBack] | 8
+		}
+	}
+}
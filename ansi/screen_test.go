@@ -0,0 +1,146 @@
+package ansi_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/Defacto2/helper/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleRender() {
+	art := "\x1b[2J\x1b[1;33mHello\x1b[10Cworld\r\n\x1b[5;40H!\x1a\x1b[99B"
+	s := ansi.Render([]byte(art), ansi.Options{})
+	fmt.Println(s.Columns(), "x", s.Rows())
+	// Output:
+	// 40 x 5
+}
+
+// line returns the characters of row y.
+func line(s *ansi.Screen, y int) string {
+	b := []byte{}
+	for _, c := range s.Row(y) {
+		b = append(b, c.Char)
+	}
+	return string(bytes.TrimRight(b, " "))
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+	s := ansi.Render(nil, ansi.Options{})
+	assert.Equal(t, ansi.Width, s.Width())
+	assert.Equal(t, 0, s.Columns())
+	assert.Equal(t, 0, s.Rows())
+	assert.Nil(t, s.Row(0))
+	assert.Equal(t, byte(' '), s.Cell(0, 0).Char)
+
+	s = ansi.Render([]byte("abc\r\n\r\ndef\tg\x08h\rX"), ansi.Options{})
+	assert.Equal(t, 3, s.Rows())
+	assert.Equal(t, 9, s.Columns())
+	assert.Equal(t, "abc", line(s, 0))
+	assert.Empty(t, line(s, 1))
+	assert.Equal(t, "Xef     h", line(s, 2))
+
+	// unix line endings
+	s = ansi.Render([]byte("abc\ndef\n"), ansi.Options{})
+	assert.Equal(t, 2, s.Rows())
+	assert.Equal(t, "def", line(s, 1))
+}
+
+func TestRenderCursor(t *testing.T) {
+	t.Parallel()
+	s := ansi.Render([]byte("\x1b[3;5Hx\x1b[Ay\x1b[2Dz\x1b[2Bw\x1b[sq\x1b[1;1Hr\x1b[uv"), ansi.Options{})
+	assert.Equal(t, 4, s.Rows())
+	assert.Equal(t, "    zy", line(s, 1))
+	assert.Equal(t, "    x", line(s, 2))
+	assert.Equal(t, "     wv", line(s, 3))
+	assert.Equal(t, byte('r'), s.Cell(0, 0).Char)
+
+	// the cursor is kept inside the screen
+	s = ansi.Render([]byte("\x1b[99Aa\x1b[999Cb\x1b[999Dc\x1b[0;999Hd"), ansi.Options{Width: 40})
+	assert.Equal(t, 1, s.Rows())
+	assert.Equal(t, 40, s.Columns())
+	assert.Equal(t, byte('c'), s.Cell(0, 0).Char)
+	assert.Equal(t, byte('d'), s.Cell(39, 0).Char)
+
+	s = ansi.Render([]byte("\x1b[999999Bx"), ansi.Options{MaxRows: 50})
+	assert.Equal(t, 50, s.Rows())
+
+	// huge parameters of untrusted texts cannot overflow the cursor
+	for _, final := range "ABCDHf" {
+		huge := "a\x1b[9223372036854775807" + string(final) + "b\x1b[99999999999999999999;9223372036854775807" +
+			string(final) + "c"
+		assert.NotPanics(t, func() { ansi.Render([]byte(huge), ansi.Options{MaxRows: 50}) }, string(final))
+	}
+	s = ansi.Render([]byte("a\x1b[9223372036854775807Cb"), ansi.Options{})
+	assert.Equal(t, byte('b'), s.Cell(s.Columns()-1, 0).Char)
+}
+
+func TestRenderWrap(t *testing.T) {
+	t.Parallel()
+	text := bytes.Repeat([]byte("0123456789"), 10)
+	s := ansi.Render(text, ansi.Options{})
+	assert.Equal(t, 2, s.Rows())
+	assert.Equal(t, 80, s.Columns())
+	assert.Equal(t, "01234567890123456789", line(s, 1))
+
+	s = ansi.Render(text, ansi.Options{Width: 160})
+	assert.Equal(t, 1, s.Rows())
+	assert.Equal(t, 100, s.Columns())
+
+	s = ansi.Render(text, ansi.Options{NoWrap: true})
+	assert.Equal(t, 1, s.Rows())
+	assert.Equal(t, 80, s.Columns())
+
+	s = ansi.Render(append([]byte("\x1b[?7l"), text...), ansi.Options{})
+	assert.Equal(t, 1, s.Rows())
+}
+
+func TestRenderErase(t *testing.T) {
+	t.Parallel()
+	s := ansi.Render([]byte("abcdef\r\nghijkl\x1b[1;3H\x1b[K"), ansi.Options{})
+	assert.Equal(t, "ab", line(s, 0))
+	s = ansi.Render([]byte("abcdef\r\nghijkl\x1b[1;3H\x1b[1K"), ansi.Options{})
+	assert.Equal(t, "   def", line(s, 0))
+	s = ansi.Render([]byte("abcdef\r\nghijkl\x1b[1;3H\x1b[2K"), ansi.Options{})
+	assert.Empty(t, line(s, 0))
+	assert.Equal(t, "ghijkl", line(s, 1))
+	s = ansi.Render([]byte("abcdef\r\nghijkl\x1b[1;3H\x1b[J"), ansi.Options{})
+	assert.Equal(t, "ab", line(s, 0))
+	assert.Empty(t, line(s, 1))
+	s = ansi.Render([]byte("abcdef\r\nghijkl\x1b[2Jx"), ansi.Options{})
+	assert.Equal(t, 1, s.Rows())
+	assert.Equal(t, 1, s.Columns())
+	assert.Equal(t, "x", line(s, 0))
+}
+
+func TestRenderColors(t *testing.T) {
+	t.Parallel()
+	s := ansi.Render([]byte("a\x1b[1;31;44mb\x1b[5mc\x1b[0;7;32md\x1b[0;96;105me\x1b[0;8mf"), ansi.Options{})
+	assert.Equal(t, ansi.Cell{Char: 'a', Fore: ansi.LightGray, Back: ansi.Black}, s.Cell(0, 0))
+	assert.Equal(t, ansi.Cell{Char: 'b', Fore: ansi.LightRed, Back: ansi.Blue}, s.Cell(1, 0))
+	assert.Equal(t, ansi.Cell{Char: 'c', Fore: ansi.LightRed, Back: ansi.Blue, Blink: true}, s.Cell(2, 0))
+	assert.Equal(t, ansi.Cell{Char: 'd', Fore: ansi.Black, Back: ansi.Green}, s.Cell(3, 0))
+	assert.Equal(t, ansi.Cell{Char: 'e', Fore: ansi.LightCyan, Back: ansi.Magenta}, s.Cell(4, 0))
+	assert.Equal(t, ansi.Cell{Char: 'f', Fore: ansi.Black, Back: ansi.Black}, s.Cell(5, 0))
+
+	// iCE colors use the blink attribute for bright backgrounds
+	s = ansi.Render([]byte("\x1b[5;44mx\x1b[0;105my"), ansi.Options{ICEColors: true})
+	assert.True(t, s.ICEColors())
+	assert.Equal(t, ansi.Cell{Char: 'x', Fore: ansi.LightGray, Back: ansi.LightBlue}, s.Cell(0, 0))
+	assert.Equal(t, ansi.Cell{Char: 'y', Fore: ansi.LightGray, Back: ansi.LightMagenta}, s.Cell(1, 0))
+	s = ansi.Render([]byte("\x1b[?33h\x1b[5;44mx"), ansi.Options{})
+	assert.True(t, s.ICEColors())
+	assert.Equal(t, ansi.LightBlue, s.Cell(0, 0).Back)
+}
+
+func TestRenderEOF(t *testing.T) {
+	t.Parallel()
+	s := ansi.Render([]byte("Hello\x1aSAUCE00"), ansi.Options{})
+	assert.Equal(t, "Hello", line(s, 0))
+	assert.Equal(t, 5, s.Columns())
+	x, y := s.Cursor()
+	assert.Equal(t, 5, x)
+	assert.Equal(t, 0, y)
+}
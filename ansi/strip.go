@@ -0,0 +1,51 @@
+package ansi
+
+import (
+	"bytes"
+	"io"
+)
+
+// MaxForward is the maximum number of spaces used to replace a cursor forward sequence.
+const MaxForward = 255
+
+// Strip returns the text of p without the ANSI escape sequences, ANSI music and
+// the control characters other than the tab, line feed and carriage return.
+//
+// As ANSI art often uses the cursor forward sequence, ESC [ n C, in place of spaces,
+// it is replaced with n spaces so that the words of the text stay apart.
+func Strip(p []byte) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, len(p)))
+	_ = WriteStrip(buf, p)
+	return buf.Bytes()
+}
+
+// WriteStrip writes the text of p without the ANSI escape sequences to w.
+// See Strip for the details.
+func WriteStrip(w io.Writer, p []byte) error {
+	l := NewLexer(p)
+	for {
+		t, ok := l.Next()
+		if !ok {
+			return nil
+		}
+		var err error
+		switch t.Kind {
+		case Text:
+			_, err = w.Write(t.Raw)
+		case Control:
+			switch t.Final {
+			case HT, LF, CR:
+				_, err = w.Write(t.Raw)
+			}
+		case Sequence:
+			if t.Final == 'C' && t.Private == 0 {
+				n := min(t.Param(0, 1), MaxForward)
+				_, err = w.Write(bytes.Repeat([]byte{' '}, n))
+			}
+		case Escape, Command, Music:
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
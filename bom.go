@@ -0,0 +1,126 @@
+package helper
+
+// Package file bom.go contains the detection of byte order marks and the UTF-16 and UTF-32 encodings.
+
+import (
+	"bytes"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+const (
+	RuleBOM  Rule = "byte order mark" // a Unicode byte order mark at the start of the text
+	RuleWide Rule = "wide characters" // a pattern of null bytes used by BOM-less UTF-16 or UTF-32 text
+)
+
+// The byte order marks of the Unicode encodings.
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+	bomUTF32LE = []byte{0xff, 0xfe, 0x00, 0x00}
+	bomUTF32BE = []byte{0x00, 0x00, 0xfe, 0xff}
+)
+
+// BOM returns the Unicode encoding and the length of the byte order mark at the start of p.
+// If there is no byte order mark then nil and 0 are returned.
+//
+// The returned UTF-16 and UTF-32 encodings expect a byte order mark, which is removed when decoding.
+// The UTF-8 encoding also removes the byte order mark when decoding.
+func BOM(p []byte) (encoding.Encoding, int) {
+	switch {
+	case bytes.HasPrefix(p, bomUTF32LE):
+		return utf32.UTF32(utf32.LittleEndian, utf32.ExpectBOM), len(bomUTF32LE)
+	case bytes.HasPrefix(p, bomUTF32BE):
+		return utf32.UTF32(utf32.BigEndian, utf32.ExpectBOM), len(bomUTF32BE)
+	case bytes.HasPrefix(p, bomUTF8):
+		return unicode.UTF8BOM, len(bomUTF8)
+	case bytes.HasPrefix(p, bomUTF16LE):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), len(bomUTF16LE)
+	case bytes.HasPrefix(p, bomUTF16BE):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), len(bomUTF16BE)
+	}
+	return nil, 0
+}
+
+// Wide returns the UTF-16 or UTF-32 encoding of a text that has no byte order mark,
+// or nil if the text does not look like either encoding.
+//
+// Wide texts that mostly use Latin characters have a distinctive pattern of null bytes,
+// for example the text "Hi" is 48 00 69 00 in UTF-16LE and 00 48 00 69 in UTF-16BE.
+// The returned encodings ignore any byte order marks.
+func Wide(p []byte) encoding.Encoding {
+	const minimum = 4
+	if len(p) < minimum {
+		return nil
+	}
+	if e := wide32(p); e != nil {
+		return e
+	}
+	return wide16(p)
+}
+
+// wide32 returns the UTF-32 encoding of the text if every group of four bytes
+// holds a character that uses no more than the 21 bits of a Unicode code point.
+func wide32(p []byte) encoding.Encoding {
+	const size = 4
+	groups := len(p) / size
+	le, be := 0, 0
+	for i := range groups {
+		b := p[i*size : i*size+size]
+		if b[2] <= 0x10 && b[3] == 0 && b[0]|b[1] != 0 {
+			le++
+		}
+		if b[0] == 0 && b[1] <= 0x10 && b[2]|b[3] != 0 {
+			be++
+		}
+	}
+	switch {
+	case le == groups:
+		return utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM)
+	case be == groups:
+		return utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM)
+	}
+	return nil
+}
+
+// wide16 returns the UTF-16 encoding of the text if at least half of the
+// high bytes of the 16-bit code units are null, while the low bytes are not.
+func wide16(p []byte) encoding.Encoding {
+	const size = 2
+	units := len(p) / size
+	even, odd := 0, 0
+	for i := range units {
+		if p[i*size] == 0 {
+			even++
+		}
+		if p[i*size+1] == 0 {
+			odd++
+		}
+	}
+	const most, few = 2, 10 // at least half of the units, and no more than a tenth
+	switch {
+	case odd*most >= units && even*few <= units:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case even*most >= units && odd*few <= units:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	}
+	return nil
+}
+
+// unicodes matches a byte order mark or the pattern of wide characters,
+// and returns the matched encoding or nil.
+func (x *examination) unicodes(p []byte) encoding.Encoding {
+	if e, n := BOM(p); e != nil {
+		x.add(RuleBOM, 0, p[:n], e)
+		return e
+	}
+	e := Wide(p)
+	if e != nil {
+		const sample = 4
+		x.add(RuleWide, 0, p[:min(sample, len(p))], e)
+	}
+	return e
+}
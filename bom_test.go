@@ -0,0 +1,91 @@
+package helper_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+func TestBOM(t *testing.T) {
+	t.Parallel()
+	e, n := helper.BOM(nil)
+	assert.Nil(t, e)
+	assert.Equal(t, 0, n)
+
+	tests := []struct {
+		name   string
+		text   []byte
+		expect encoding.Encoding
+		length int
+	}{
+		{"utf-8", []byte("\xef\xbb\xbfHi"), unicode.UTF8BOM, 3},
+		{"utf-16le", []byte("\xff\xfeH\x00"), unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), 2},
+		{"utf-16be", []byte("\xfe\xff\x00H"), unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), 2},
+		{"utf-32le", []byte("\xff\xfe\x00\x00H\x00\x00\x00"), utf32.UTF32(utf32.LittleEndian, utf32.ExpectBOM), 4},
+		{"utf-32be", []byte("\x00\x00\xfe\xff\x00\x00\x00H"), utf32.UTF32(utf32.BigEndian, utf32.ExpectBOM), 4},
+		{"none", []byte("Hello"), nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			e, n := helper.BOM(tt.text)
+			assert.Equal(t, tt.expect, e)
+			assert.Equal(t, tt.length, n)
+			if tt.expect != nil {
+				assert.Equal(t, tt.expect, helper.Determine(bytes.NewReader(tt.text)))
+			}
+		})
+	}
+}
+
+func TestWide(t *testing.T) {
+	t.Parallel()
+	const s = "Hello world, these are wide characters!"
+	le16 := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	be16 := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	le32 := utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM)
+	be32 := utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM)
+	for _, e := range []encoding.Encoding{le16, be16, le32, be32} {
+		p, err := e.NewEncoder().Bytes([]byte(s))
+		require.NoError(t, err)
+		assert.Equal(t, e, helper.Wide(p), "wanted %s", e)
+		d, err := helper.Detect(bytes.NewReader(p))
+		require.NoError(t, err)
+		assert.Equal(t, e, d.Encoding(), "wanted %s", e)
+		assert.Equal(t, 1, d.Tally[helper.RuleWide])
+	}
+	assert.Nil(t, helper.Wide([]byte(s)))
+	assert.Nil(t, helper.Wide(make([]byte, 64)))
+	assert.Nil(t, helper.Wide([]byte{0x48, 0x00}))
+}
+
+func TestUTF8BOM(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		text   []byte
+		expect bool
+	}{
+		{"utf8.txt", []byte("\xef\xbb\xbfHello"), true},
+		{"utf16.txt", []byte("\xff\xfeH\x00i\x00"), false},
+		{"wide.txt", []byte("H\x00e\x00l\x00l\x00o\x00"), false},
+		{"short.txt", []byte("Hi"), true},
+	}
+	for _, tt := range tests {
+		name := filepath.Join(dir, tt.name)
+		err := os.WriteFile(name, tt.text, helper.WriteWriteRead)
+		require.NoError(t, err)
+		ok, err := helper.UTF8(name)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expect, ok, tt.name)
+	}
+}
@@ -0,0 +1,51 @@
+package codepage
+
+import "strings"
+
+// CSI is the Amiga control sequence introducer, which is used in place of the ESC [ characters.
+const CSI = 0x9b
+
+// Topaz is the ISO-8859-1 character set as drawn by the Amiga Topaz font of the Workbench and the shell.
+//
+// The delete character, 0x7f, and the undefined characters 0x80 to 0x9f
+// have no glyphs of their own and are drawn using the hatched block of the font,
+// which is decoded as U+1FB99.
+// The soft hyphen, 0xad, is drawn as a hyphen and decoded as U+2010.
+// The control codes, 0x00 to 0x1f, and the control sequence introducer, CSI,
+// are decoded to the Unicode control characters of the same value.
+var Topaz = newCharmap("Amiga Topaz", amiga('\U0001FB99'))
+
+// MicroKnight is the ISO-8859-1 character set as drawn by the Amiga MicroKnight font,
+// which was a popular replacement for the Topaz font with the art scene.
+// It is the same as Topaz, except the characters without glyphs are drawn
+// using the checkerboard block of the font, which is decoded as U+2592.
+var MicroKnight = newCharmap("Amiga MicroKnight", amiga('▒'))
+
+// amiga returns the runes of the Amiga character sets using the block for the characters without glyphs.
+func amiga(block rune) [256]rune {
+	var t [256]rune
+	for i := range t {
+		t[i] = rune(i)
+	}
+	for i := 0x7f; i < 0xa0; i++ {
+		t[i] = block
+	}
+	t[CSI] = CSI
+	t[0xad] = '‐'
+	return t
+}
+
+// Amiga returns the Amiga character set of the SAUCE font name,
+// or nil if the font is not an Amiga font.
+// The MicroKnight fonts return MicroKnight and all other Amiga fonts,
+// such as "Amiga Topaz 2+" or "Amiga P0T-NOoDLE", return Topaz.
+func Amiga(font string) *Charmap {
+	const prefix = "Amiga "
+	if !strings.HasPrefix(font, prefix) {
+		return nil
+	}
+	if strings.HasPrefix(font, prefix+"MicroKnight") {
+		return MicroKnight
+	}
+	return Topaz
+}
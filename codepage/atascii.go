@@ -0,0 +1,34 @@
+package codepage
+
+// atasciiGraphics are the graphic characters of ATASCII 0x00 to 0x1f.
+var atasciiGraphics = [32]rune{
+	'♥', '├', '\U0001FB87', '┘', '┤', '┐', '╱', '╲',
+	'◢', '▗', '◣', '▝', '▘', '\U0001FB82', '▂', '▖',
+	'♣', '┌', '─', '┼', '●', '▄', '▎', '┬',
+	'┴', '▌', '└', '␛', '↑', '↓', '←', '→',
+}
+
+// EOL is the ATASCII end of line character.
+const EOL = 0x9b
+
+// ATASCII is the character set of the Atari 8-bit computers.
+//
+// The characters 0x80 to 0xff are the inverse video of 0x00 to 0x7f and so decode
+// to the same runes, except for the end of line character, EOL, which is a line feed.
+// The encoder only uses the characters 0x00 to 0x7f and the EOL.
+var ATASCII = newCharmap("ATASCII", atascii())
+
+// atascii returns the runes of the ATASCII character set.
+func atascii() [256]rune {
+	var t [256]rune
+	copy(t[:], atasciiGraphics[:])
+	for i := 0x20; i < 0x80; i++ {
+		t[i] = rune(i)
+	}
+	t[0x60], t[0x7b], t[0x7d], t[0x7e], t[0x7f] = '♦', '♠', '↰', '◀', '▶'
+	for i := 0x80; i < 0x100; i++ {
+		t[i] = t[i-0x80]
+	}
+	t[EOL] = '\n'
+	return t
+}
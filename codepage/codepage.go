@@ -0,0 +1,139 @@
+// Package codepage provides the 8-bit character encodings of the home computers
+// and the text modes that are not found in golang.org/x/text, such as the
+// Commodore PETSCII and Atari ATASCII character sets, and the Amiga Topaz
+// and MicroKnight fonts.
+//
+// Each Charmap implements the encoding.Encoding interface,
+// so it can be used with the transform and encoding packages of golang.org/x/text.
+package codepage
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// Replacement is the character that replaces a rune that cannot be encoded,
+// when using the encoding.ReplaceUnsupported encoder.
+const Replacement = '?'
+
+// Charmap is an 8-bit character encoding.
+type Charmap struct {
+	name   string
+	decode [256]rune
+	encode map[rune]byte
+}
+
+// newCharmap returns a character encoding using the decoded runes of every byte.
+// When more than one byte decodes to a rune, the rune is encoded using the lowest byte.
+func newCharmap(name string, decode [256]rune) *Charmap {
+	c := &Charmap{
+		name:   name,
+		decode: decode,
+		encode: make(map[rune]byte, len(decode)),
+	}
+	for i, r := range decode {
+		if _, ok := c.encode[r]; !ok {
+			c.encode[r] = byte(i)
+		}
+	}
+	return c
+}
+
+// String returns the name of the character encoding.
+func (c *Charmap) String() string {
+	return c.name
+}
+
+// DecodeByte returns the rune of the byte.
+func (c *Charmap) DecodeByte(b byte) rune {
+	return c.decode[b]
+}
+
+// EncodeRune returns the byte of the rune,
+// or false if the rune is not in the character set.
+func (c *Charmap) EncodeRune(r rune) (byte, bool) {
+	b, ok := c.encode[r]
+	return b, ok
+}
+
+// NewDecoder returns a decoder of the character encoding to UTF-8.
+func (c *Charmap) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: decoder{c}}
+}
+
+// NewEncoder returns an encoder of UTF-8 to the character encoding.
+// Runes that are not in the character set return an error,
+// unless the encoder is wrapped by encoding.ReplaceUnsupported.
+func (c *Charmap) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: encoder{c}}
+}
+
+// repertoireError is returned when a rune is not in the character set.
+// The Replacement method is used by encoding.ReplaceUnsupported.
+type repertoireError byte
+
+// Error implements the error interface.
+func (r repertoireError) Error() string {
+	return "codepage: rune not supported by encoding"
+}
+
+// Replacement returns the replacement character of the rune.
+func (r repertoireError) Replacement() byte {
+	return byte(r)
+}
+
+// decoder is the transformer of a character encoding to UTF-8.
+type decoder struct {
+	c *Charmap
+}
+
+// Reset implements the transform.Transformer interface.
+func (decoder) Reset() {}
+
+// Transform implements the transform.Transformer interface.
+func (d decoder) Transform(dst, src []byte, _ bool) (int, int, error) {
+	nDst, nSrc := 0, 0
+	for ; nSrc < len(src); nSrc++ {
+		r := d.c.decode[src[nSrc]]
+		if nDst+utf8.RuneLen(r) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+	}
+	return nDst, nSrc, nil
+}
+
+// encoder is the transformer of UTF-8 to a character encoding.
+type encoder struct {
+	c *Charmap
+}
+
+// Reset implements the transform.Transformer interface.
+func (encoder) Reset() {}
+
+// Transform implements the transform.Transformer interface.
+func (e encoder) Transform(dst, src []byte, atEOF bool) (int, int, error) {
+	nDst, nSrc := 0, 0
+	for nSrc < len(src) {
+		if nDst >= len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		r, size := rune(src[nSrc]), 1
+		if r >= utf8.RuneSelf {
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			r, size = utf8.DecodeRune(src[nSrc:])
+		}
+		b, ok := e.c.encode[r]
+		if !ok || (r == utf8.RuneError && size == 1) {
+			return nDst, nSrc, repertoireError(Replacement)
+		}
+		dst[nDst] = b
+		nDst++
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
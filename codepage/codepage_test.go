@@ -0,0 +1,150 @@
+package codepage_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Defacto2/helper/codepage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func ExampleCharmap_NewDecoder() {
+	s, _ := codepage.PETSCIIShifted.NewDecoder().String("\xc8ELLO \xd7ORLD")
+	fmt.Println(s)
+	// Output:
+	// Hello World
+}
+
+func TestPETSCII(t *testing.T) {
+	t.Parallel()
+	c := codepage.PETSCII
+	assert.Equal(t, "PETSCII (upper case and graphics)", c.String())
+	assert.Equal(t, 'A', c.DecodeByte('A'))
+	assert.Equal(t, '£', c.DecodeByte(0x5c))
+	assert.Equal(t, '♥', c.DecodeByte(0x73))
+	assert.Equal(t, '♥', c.DecodeByte(0xd3))
+	assert.Equal(t, '▌', c.DecodeByte(0xa1))
+	assert.Equal(t, 'π', c.DecodeByte(0xff))
+	assert.Equal(t, '\r', c.DecodeByte(0x0d))
+
+	c = codepage.PETSCIIShifted
+	assert.Equal(t, 'a', c.DecodeByte('A'))
+	assert.Equal(t, 'A', c.DecodeByte(0x61))
+	assert.Equal(t, 'Z', c.DecodeByte(0xda))
+	assert.Equal(t, '✓', c.DecodeByte(0xba))
+	b, ok := c.EncodeRune('A')
+	assert.True(t, ok)
+	assert.Equal(t, byte(0x61), b, "the lowest byte is used for a duplicate rune")
+	_, ok = c.EncodeRune('€')
+	assert.False(t, ok)
+}
+
+func TestATASCII(t *testing.T) {
+	t.Parallel()
+	c := codepage.ATASCII
+	assert.Equal(t, "ATASCII", c.String())
+	assert.Equal(t, '\n', c.DecodeByte(codepage.EOL))
+	assert.Equal(t, '♦', c.DecodeByte(0x60))
+	assert.Equal(t, '♠', c.DecodeByte(0x7b))
+	assert.Equal(t, 'A', c.DecodeByte(0xc1))
+	b, ok := c.EncodeRune('\n')
+	assert.True(t, ok)
+	assert.Equal(t, byte(codepage.EOL), b)
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	for _, c := range []*codepage.Charmap{codepage.PETSCII, codepage.PETSCIIShifted, codepage.ATASCII, codepage.Topaz} {
+		s, err := c.NewDecoder().String("READY.\x0d\x9bHELLO ♥")
+		require.NoError(t, err, c)
+		p, err := c.NewEncoder().String(s)
+		require.NoError(t, err, c)
+		s2, err := c.NewDecoder().String(p)
+		require.NoError(t, err, c)
+		assert.Equal(t, s, s2, c)
+	}
+}
+
+func TestEncoderUnsupported(t *testing.T) {
+	t.Parallel()
+	_, err := codepage.ATASCII.NewEncoder().String("caf€")
+	require.Error(t, err)
+	s, err := encoding.ReplaceUnsupported(codepage.ATASCII.NewEncoder()).String("caf€ \xff")
+	require.NoError(t, err)
+	assert.Equal(t, "caf? ?", s)
+}
+
+func TestAmiga(t *testing.T) {
+	t.Parallel()
+	c := codepage.Topaz
+	assert.Equal(t, "Amiga Topaz", c.String())
+	assert.Equal(t, 'é', c.DecodeByte(0xe9))
+	assert.Equal(t, '\U0001FB99', c.DecodeByte(0x7f))
+	assert.Equal(t, '\U0001FB99', c.DecodeByte(0x85))
+	assert.Equal(t, rune(codepage.CSI), c.DecodeByte(codepage.CSI))
+	assert.Equal(t, '‐', c.DecodeByte(0xad))
+	assert.Equal(t, '▒', codepage.MicroKnight.DecodeByte(0x7f))
+	b, ok := c.EncodeRune('\U0001FB99')
+	assert.True(t, ok)
+	assert.Equal(t, byte(0x7f), b)
+
+	assert.Equal(t, codepage.Topaz, codepage.Amiga("Amiga Topaz 2+"))
+	assert.Equal(t, codepage.Topaz, codepage.Amiga("Amiga P0T-NOoDLE"))
+	assert.Equal(t, codepage.MicroKnight, codepage.Amiga("Amiga MicroKnight+"))
+	assert.Nil(t, codepage.Amiga("IBM VGA"))
+	assert.Nil(t, codepage.Amiga(""))
+}
+
+func ExampleCodePage437() {
+	s, _ := codepage.CodePage437(codepage.KeepNewlines).NewDecoder().String("\x01 \x03 \x0e\r\n")
+	fmt.Printf("%q\n", s)
+	// Output:
+	// "☺ ♥ ♫\r\n"
+}
+
+func TestCodePage437(t *testing.T) {
+	t.Parallel()
+	c := codepage.CodePage437(0)
+	assert.Equal(t, "IBM Code Page 437 (glyphs)", c.String())
+	assert.Equal(t, rune(0), c.DecodeByte(0x00))
+	assert.Equal(t, '☺', c.DecodeByte(0x01))
+	assert.Equal(t, '◙', c.DecodeByte('\n'))
+	assert.Equal(t, '♪', c.DecodeByte('\r'))
+	assert.Equal(t, '○', c.DecodeByte('\t'))
+	assert.Equal(t, '←', c.DecodeByte(0x1b))
+	assert.Equal(t, '⌂', c.DecodeByte(0x7f))
+	assert.Equal(t, 'Ç', c.DecodeByte(0x80))
+	assert.Equal(t, '█', c.DecodeByte(0xdb))
+	assert.Equal(t, 'A', c.DecodeByte('A'))
+
+	c = codepage.CodePage437(codepage.KeepControls)
+	assert.Equal(t, '\n', c.DecodeByte('\n'))
+	assert.Equal(t, '\r', c.DecodeByte('\r'))
+	assert.Equal(t, '\t', c.DecodeByte('\t'))
+	assert.Equal(t, rune(0x1b), c.DecodeByte(0x1b))
+	assert.Equal(t, '♥', c.DecodeByte(0x03))
+	_, ok := c.EncodeRune('◙')
+	assert.False(t, ok, "the glyph of a kept control is not in the character set")
+
+	c = codepage.CodePage437(codepage.KeepEsc)
+	assert.Equal(t, rune(0x1b), c.DecodeByte(0x1b))
+	assert.Equal(t, '◙', c.DecodeByte('\n'))
+	assert.Same(t, c, codepage.CodePage437(codepage.KeepEsc))
+
+	p, err := codepage.CodePage437(codepage.KeepNewlines).NewEncoder().String("☺ ⌂ ░▒▓\r\n")
+	require.NoError(t, err)
+	assert.Equal(t, "\x01 \x7f \xb0\xb1\xb2\r\n", p)
+}
+
+func TestIsCodePage437(t *testing.T) {
+	t.Parallel()
+	assert.True(t, codepage.IsCodePage437(charmap.CodePage437))
+	assert.True(t, codepage.IsCodePage437(codepage.CodePage437(0)))
+	assert.True(t, codepage.IsCodePage437(codepage.CodePage437(codepage.KeepControls)))
+	assert.False(t, codepage.IsCodePage437(charmap.CodePage850))
+	assert.False(t, codepage.IsCodePage437(codepage.Topaz))
+	assert.False(t, codepage.IsCodePage437(nil))
+}
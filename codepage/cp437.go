@@ -0,0 +1,81 @@
+package codepage
+
+import (
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Controls are the control characters that the CodePage437 glyph encoding keeps as controls.
+type Controls uint8
+
+const (
+	KeepCR  Controls = 1 << iota // KeepCR keeps the carriage return, 0x0d, instead of ♪.
+	KeepLF                       // KeepLF keeps the line feed, 0x0a, instead of ◙.
+	KeepTab                      // KeepTab keeps the horizontal tab, 0x09, instead of ○.
+	KeepEsc                      // KeepEsc keeps the escape, 0x1b, instead of ←, for the ANSI escape sequences.
+
+	KeepNewlines = KeepCR | KeepLF                     // KeepNewlines keeps the CR and LF line endings.
+	KeepControls = KeepCR | KeepLF | KeepTab | KeepEsc // KeepControls keeps all the controls used by plain text and ANSI art.
+)
+
+// cp437Glyphs are the CP-437 glyphs of the control characters 0x01 to 0x1f.
+var cp437Glyphs = [32]rune{
+	0x00, '☺', '☻', '♥', '♦', '♣', '♠', '•', '◘', '○', '◙', '♂', '♀', '♪', '♫', '☼',
+	'►', '◄', '↕', '‼', '¶', '§', '▬', '↨', '↑', '↓', '→', '←', '∟', '↔', '▲', '▼',
+}
+
+// cp437 are the glyph encodings of every combination of the kept controls.
+var cp437 = func() [KeepControls + 1]*Charmap {
+	var c [KeepControls + 1]*Charmap
+	for keep := range c {
+		c[keep] = newCharmap("IBM Code Page 437 (glyphs)", cp437Table(Controls(keep)))
+	}
+	return c
+}()
+
+// CodePage437 returns the IBM PC character set that decodes the control characters,
+// 0x01 to 0x1f, and the delete character, 0x7f, as the glyphs drawn by the MS-DOS text mode,
+// such as ☺ ♥ ♪ ► and ⌂. This differs from charmap.CodePage437, which decodes them as controls.
+//
+// The keep controls are decoded as the control characters, which is usually wanted
+// for the line endings and the escape character of ANSI art,
+// but then the glyphs of the kept controls cannot be encoded.
+// The null character, 0x00, is always a control.
+func CodePage437(keep Controls) *Charmap {
+	return cp437[keep&KeepControls]
+}
+
+// IsCodePage437 returns true if the encoding is charmap.CodePage437 or any of the CodePage437 glyph encodings.
+func IsCodePage437(e encoding.Encoding) bool {
+	if e == charmap.CodePage437 {
+		return true
+	}
+	for _, c := range cp437 {
+		if e == c {
+			return true
+		}
+	}
+	return false
+}
+
+// cp437Table returns the runes of the CP-437 glyph encoding with the kept controls.
+func cp437Table(keep Controls) [256]rune {
+	var t [256]rune
+	for i := range t {
+		t[i] = charmap.CodePage437.DecodeByte(byte(i))
+	}
+	copy(t[:], cp437Glyphs[:])
+	t[0x7f] = '⌂'
+	controls := []struct {
+		keep Controls
+		char byte
+	}{
+		{KeepCR, '\r'}, {KeepLF, '\n'}, {KeepTab, '\t'}, {KeepEsc, 0x1b},
+	}
+	for _, c := range controls {
+		if keep&c.keep != 0 {
+			t[c.char] = rune(c.char)
+		}
+	}
+	return t
+}
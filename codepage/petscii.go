@@ -0,0 +1,69 @@
+package codepage
+
+// petsciiGraphics are the graphic characters of PETSCII 0x60 to 0x7f, which repeat at 0xc0 to 0xdf.
+var petsciiGraphics = [32]rune{
+	'─', '♠', '\U0001FB72', '\U0001FB78', '\U0001FB77', '\U0001FB76', '\U0001FB7A', '\U0001FB71',
+	'\U0001FB74', '╮', '╰', '╯', '\U0001FB7C', '╲', '╱', '\U0001FB7D',
+	'\U0001FB7E', '●', '\U0001FB7B', '♥', '\U0001FB70', '╭', '╳', '○',
+	'♣', '\U0001FB75', '♦', '┼', '\U0001FB8C', '│', 'π', '◥',
+}
+
+// petsciiBlocks are the block characters of PETSCII 0xa0 to 0xbf, which repeat at 0xe0 to 0xfe.
+var petsciiBlocks = [32]rune{
+	'\u00a0', '▌', '▄', '▔', '▁', '▏', '▒', '▕',
+	'\U0001FB8F', '◤', '\U0001FB87', '├', '▗', '└', '┐', '▂',
+	'┌', '┴', '┬', '┤', '▎', '▍', '\U0001FB88', '\U0001FB82',
+	'\U0001FB83', '▃', '\U0001FB7F', '▖', '▝', '┘', '▘', '▚',
+}
+
+// PETSCII is the upper case and graphics character set of the Commodore 64 and other 8-bit
+// Commodore computers, which is the character set used when the computer is switched on.
+//
+// The graphic characters use the Symbols for Legacy Computing block of Unicode 13.
+// The control codes for the colors and the cursor, 0x00 to 0x1f and 0x80 to 0x9f,
+// are decoded to the Unicode control characters of the same value,
+// so the return key, 0x0d, is a carriage return.
+var PETSCII = newCharmap("PETSCII (upper case and graphics)", petscii(false))
+
+// PETSCIIShifted is the lower and upper case character set of the Commodore 64 and other 8-bit
+// Commodore computers, which is selected by the Commodore and shift keys or the 0x0e control code.
+// Most text documents use this character set, where 0x41 to 0x5a are lower case letters
+// and 0xc1 to 0xda are the upper case letters.
+var PETSCIIShifted = newCharmap("PETSCII (lower and upper case)", petscii(true))
+
+// petscii returns the runes of the PETSCII character sets.
+func petscii(shifted bool) [256]rune {
+	var t [256]rune
+	for i := range 0x40 {
+		t[i] = rune(i)
+	}
+	for i := 0x40; i < 0x60; i++ {
+		t[i] = rune(i)
+		if shifted && i >= 'A' && i <= 'Z' {
+			t[i] = rune(i) + 'a' - 'A'
+		}
+	}
+	t[0x5c], t[0x5e], t[0x5f] = '£', '↑', '←'
+	for i, r := range petsciiGraphics {
+		t[0x60+i], t[0xc0+i] = r, r
+	}
+	for i := 0x80; i < 0xa0; i++ {
+		t[i] = rune(i)
+	}
+	for i, r := range petsciiBlocks {
+		t[0xa0+i], t[0xe0+i] = r, r
+	}
+	t[0xff] = 'π'
+	if !shifted {
+		return t
+	}
+	for i := 'A'; i <= 'Z'; i++ {
+		t[0x60+i-'@'], t[0xc0+i-'@'] = i, i
+	}
+	const checkerboard, triangle = '\U0001FB96', '\U0001FB98'
+	t[0x7e], t[0xde], t[0xff] = checkerboard, checkerboard, checkerboard
+	t[0x7f], t[0xdf] = triangle, triangle
+	t[0xa9], t[0xe9] = '\U0001FB99', '\U0001FB99'
+	t[0xba], t[0xfa] = '✓', '✓'
+	return t
+}
@@ -0,0 +1,572 @@
+package helper
+
+// Package file detect.go contains the explainable, plain text encoding detection.
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/Defacto2/helper/codepage"
+	"github.com/Defacto2/helper/sauce"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// EvidenceLimit is the maximum number of evidence items kept for each rule.
+// Every match is still counted in the Detection tally.
+const EvidenceLimit = 100
+
+// Rule is the name of a detection rule that matched the text.
+type Rule string
+
+const (
+	RuleControl     Rule = "control character"       // an ASCII control character that is likely a CP-437 glyph
+	RuleUndefined   Rule = "undefined character"     // a character that is unused by ISO-8859-1, 0x7f to 0x9f
+	RuleSequence    Rule = "cp-437 sequence"         // a run of block, line or bullet characters common to CP-437
+	RuleExtended    Rule = "extended character"      // a printable ISO-8859-1 character, 0xa0 to 0xff
+	RuleMultibyte   Rule = "multibyte rune"          // a valid, multi-byte UTF-8 encoded character
+	RuleInvalid     Rule = "invalid rune"            // the first byte that is not valid UTF-8
+	RulePunctuation Rule = "typographic punctuation" // a Windows-1252 quote, dash, ellipsis, bullet or symbol in context
+	RuleAccent      Rule = "accented letter"         // a CP-437 accented letter within a word
+	RuleUnassigned  Rule = "unassigned character"    // a character that is unused by Windows-1252
+	RuleDrawing     Rule = "box drawing character"   // a CP-437 block, shade or line drawing character, 0xb0 to 0xdf
+)
+
+// Evidence is a match of a detection rule in the text.
+type Evidence struct {
+	Rule     Rule   // Rule is the detection rule that was matched.
+	Offset   int64  // Offset is the position of the first matched byte in the text.
+	Value    []byte // Value is a copy of the matched bytes.
+	Supports string // Supports is the name of the encoding the match is evidence for.
+}
+
+// Candidate is an encoding that could have been used by the text.
+type Candidate struct {
+	Encoding   encoding.Encoding // Encoding is the text encoding.
+	Name       string            // Name is the human readable name of the encoding.
+	Confidence float64           // Confidence is the likelihood of the encoding, from 0 to 1.
+	Score      int               // Score is the number of rule matches that support the encoding.
+}
+
+// Detection is the explained result of the encoding detection of a text.
+type Detection struct {
+	Candidates []Candidate  // Candidates are the possible encodings, ranked by confidence.
+	Evidence   []Evidence   // Evidence are the rule matches in byte order, limited by EvidenceLimit per rule.
+	Tally      map[Rule]int // Tally is the total number of matches of each rule.
+	Size       int64        // Size is the number of bytes that were examined.
+}
+
+// Encoding returns the most likely encoding of the detection,
+// or nil if there are no candidates.
+func (d Detection) Encoding() encoding.Encoding {
+	if len(d.Candidates) == 0 {
+		return nil
+	}
+	return d.Candidates[0].Encoding
+}
+
+// Detect reads the plain text and returns the ranked, candidate encodings
+// together with the evidence that was used to rank them.
+// The most likely candidate is always the same encoding that Determine returns.
+func Detect(reader io.Reader) (Detection, error) {
+	if reader == nil {
+		return Detection{}, ErrReader
+	}
+	p, err := io.ReadAll(reader)
+	if err != nil {
+		return Detection{}, fmt.Errorf("detect read all %w", err)
+	}
+	return detect(p), nil
+}
+
+// detect returns the detection of the byte slice.
+// Any SAUCE metadata and MS-DOS end-of-file markers at the end of the byte slice are ignored.
+func detect(p []byte) Detection {
+	x := examine(sauce.Trim(p))
+	x.font(p)
+	return x.detection()
+}
+
+// examination is the collection of rule matches found in a text.
+type examination struct {
+	evidence []Evidence
+	tally    map[Rule]int
+	size     int64
+	utf8     bool // utf8 is true when the whole text is valid UTF-8
+	c1       int  // c1 is the number of characters between 0x80 and 0x9f
+	house    int  // house is the number of CP-437 house characters, 0x7f
+
+	wide encoding.Encoding // wide is the encoding of a byte order mark or a wide character text
+	home encoding.Encoding // home is the encoding of a Commodore or Atari home computer text
+
+	topaz *codepage.Charmap // topaz is the Amiga character set of the SAUCE font
+
+	dos       *charmap.Charmap         // dos is the most plausible MS-DOS code page
+	dosScores map[*charmap.Charmap]int // dosScores are the plausibility scores of the MS-DOS code pages
+	latin1    int                      // latin1 is the plausibility score of the text read as ISO-8859-1
+}
+
+// examine applies the detection rules to the byte slice.
+func examine(p []byte) *examination {
+	x := &examination{
+		tally: make(map[Rule]int),
+		size:  int64(len(p)),
+		utf8:  utf8.Valid(p),
+	}
+	// the 8-bit rules are meaningless for texts using a byte order mark or wide characters
+	if x.wide = x.unicodes(p); x.wide != nil {
+		return x
+	}
+	// and for the texts of the home computers that use their own character sets
+	if x.home = x.retro(p); x.home != nil {
+		return x
+	}
+	x.characters(p)
+	x.console(p)
+	x.sequences(p)
+	x.runes(p)
+	x.typography(p)
+	x.dos = charmap.CodePage437
+	switch {
+	case x.legacy() && !x.windows1252():
+		x.dos, x.dosScores = x.national(p)
+	case !x.legacy() && !x.utf8 && x.tally[RuleExtended] > 0:
+		// a national text can avoid the characters between 0x80 and 0x9f,
+		// such as the lowercase Cyrillic letters of CP-866, so it is compared to ISO-8859-1
+		x.dos, x.dosScores = x.national(p)
+		x.latin1 = plausibility(p, charmap.ISO8859_1)
+	}
+	sort.SliceStable(x.evidence, func(i, j int) bool {
+		return x.evidence[i].Offset < x.evidence[j].Offset
+	})
+	return x
+}
+
+// add records a rule match, but only keeps the evidence up to the EvidenceLimit.
+func (x *examination) add(rule Rule, offset int, value []byte, supports encoding.Encoding) {
+	x.tally[rule]++
+	if x.tally[rule] > EvidenceLimit {
+		return
+	}
+	x.evidence = append(x.evidence, Evidence{
+		Rule:     rule,
+		Offset:   int64(offset),
+		Value:    bytes.Clone(value),
+		Supports: EncodingName(supports),
+	})
+}
+
+// characters matches the individual control, undefined and extended characters.
+func (x *examination) characters(p []byte) {
+	const (
+		controlStart   = 0x00 // ASCII control character start
+		controlEnd     = 0x1f // ASCII control character end
+		undefinedStart = 0x7f // Latin-1 undefined characters start
+		undefinedEnd   = 0x9f // Latin-1 undefined characters end
+		extendedStart  = 0xa0 // Latin-1 printable, extended characters start
+		escape         = 0x1b // ASCII escape control character
+	)
+	// The following characters are considered whitespace characters in C
+	// with the isspace function:
+	// https://en.cppreference.com/w/c/string/byte/isspace
+	const (
+		formFeed       = '\f'
+		newline        = '\n'
+		carriageReturn = '\r'
+		tab            = '\t'
+		verticalTab    = '\v'
+	)
+
+	// KCF key qualifiers on the Commodore Amiga, see: https://wiki.amigaos.net/wiki/Keymap_Library
+
+	const (
+		kcfAltEsc = 0x9b // the Amiga had Keymap Qualifier Bits, which could be a typo to generate an Alt-Esc sequence?
+		bell      = 0x07 // ASCII bell character that is sometimes found in Amiga ANSI files
+		house     = 0x7f // CP-437 house character that displays a unique glyph in the Amiga Topaz font
+	)
+	for i, char := range p {
+		switch {
+		case x.utf8 && char > undefinedStart:
+			// part of a valid UTF-8 multi-byte character
+			continue
+		case char == escape:
+			// escape control character commonly used in ANSI escaped sequences
+			continue
+		case // oddball control characters that are sometimes found in Amiga ANSI files
+			char == kcfAltEsc,
+			char == bell:
+			continue
+		case // common whitespace control characters
+			char == formFeed,
+			char == newline,
+			char == carriageReturn,
+			char == tab,
+			char == verticalTab:
+			continue
+		case char >= undefinedStart && char <= undefinedEnd:
+			// unused ASCII, which we can probably assumed to be CP-437
+			x.add(RuleUndefined, i, p[i:i+1], charmap.CodePage437)
+		case char >= controlStart && char <= controlEnd:
+			// ASCII control characters, which we can probably assumed to be CP-437 glyphs
+			x.add(RuleControl, i, p[i:i+1], charmap.CodePage437)
+		case char >= extendedStart:
+			x.add(RuleExtended, i, p[i:i+1], charmap.ISO8859_1)
+		}
+	}
+}
+
+// sequences matches the common CP-437 character sequences.
+// Full block, medium shade, horizontal bars and half blocks are sequences of characters that are often
+// unique to the CP-437 encoding.
+func (x *examination) sequences(p []byte) {
+	const (
+		shadeLight     = 0xb0 // ░
+		shadeMedium    = 0xb1 // ▒
+		shadeDark      = 0xb2 // ▓
+		singleHorizBar = 0xc4 // ─
+		doubleHorizBar = 0xcd // ═
+		fullBlock      = 0xdb // █
+		lowerHalfBlock = 0xdc // ▄
+		upperHalfBlock = 0xdf // ▀
+		interpunct     = 0xfa // ·
+	)
+	chars := []byte{
+		lowerHalfBlock,
+		upperHalfBlock,
+		doubleHorizBar,
+		singleHorizBar,
+		fullBlock,
+		interpunct,
+		shadeLight,
+		shadeMedium,
+		shadeDark,
+	}
+	const count = 4
+	for _, char := range chars {
+		x.runs(p, bytes.Repeat([]byte{char}, count))
+	}
+	guillemets := []byte{0xae, 0xaf} // «»
+	x.runs(p, guillemets)
+	const bulletpoint = 0xf9 // ••
+	x.runs(p, []byte{bulletpoint, bulletpoint})
+}
+
+// runs matches every non-overlapping, repeated run of the subslice in p.
+func (x *examination) runs(p, subslice []byte) {
+	offset := 0
+	for {
+		i := bytes.Index(p[offset:], subslice)
+		if i < 0 {
+			return
+		}
+		start := offset + i
+		end := start + len(subslice)
+		// extend the match over the whole run of the repeated character
+		if len(subslice) > 1 && subslice[0] == subslice[len(subslice)-1] {
+			for end < len(p) && p[end] == subslice[0] {
+				end++
+			}
+		}
+		x.add(RuleSequence, start, p[start:end], charmap.CodePage437)
+		offset = end
+	}
+}
+
+// typography matches the characters that can tell Windows-1252 text apart from CP-437 text.
+// Both encodings use 0x80 to 0x9f as printable characters, but Windows-1252 places
+// its typographic punctuation there, such as smart quotes, dashes, ellipsis and the euro sign,
+// while CP-437 places its accented letters that are usually found within words.
+func (x *examination) typography(p []byte) {
+	if x.utf8 {
+		return
+	}
+	const (
+		c1Start      = 0x80 // first character of the C1 control codes in ISO-8859-1
+		c1End        = 0x9f // last character of the C1 control codes in ISO-8859-1
+		letterEnd    = 0x9a // CP-437 Ü, the last accented letter in the C1 range
+		accentStart  = 0xa0 // CP-437 á
+		accentEnd    = 0xa5 // CP-437 Ñ
+		drawingStart = 0xb0 // CP-437 ░
+		drawingEnd   = 0xdf // CP-437 ▀
+		house        = 0x7f // CP-437 ⌂
+	)
+	for i, char := range p {
+		if char == house {
+			x.house++
+			continue
+		}
+		prev, next := neighbours(p, i)
+		wordy := letter(prev) && (letter(next) || (next >= c1Start && next <= letterEnd))
+		switch {
+		case char >= drawingStart && char <= drawingEnd:
+			// in Windows-1252 these are symbols or accented capitals, that are found within words
+			if !wordy {
+				x.add(RuleDrawing, i, p[i:i+1], charmap.CodePage437)
+			}
+			continue
+		case char >= accentStart && char <= accentEnd:
+			if wordy {
+				x.add(RuleAccent, i, p[i:i+1], charmap.CodePage437)
+			}
+			continue
+		case char < c1Start || char > c1End:
+			continue
+		}
+		x.c1++
+		switch {
+		case unassigned(char):
+			x.add(RuleUnassigned, i, p[i:i+1], charmap.CodePage437)
+		case punctuation(char, prev, next):
+			x.add(RulePunctuation, i, p[i:i+1], charmap.Windows1252)
+		case wordy && char <= letterEnd:
+			x.add(RuleAccent, i, p[i:i+1], charmap.CodePage437)
+		}
+	}
+}
+
+// windows1252 returns true if the characters between 0x80 and 0x9f are more likely
+// to be Windows-1252 typographic punctuation than CP-437 accented letters and glyphs.
+func (x *examination) windows1252() bool {
+	if x.c1 == 0 {
+		return false
+	}
+	const weight = 2
+	punct := weight * x.tally[RulePunctuation]
+	cp437 := x.tally[RuleControl] + x.house + x.tally[RuleAccent] + x.tally[RuleDrawing] +
+		weight*x.tally[RuleUnassigned]
+	return punct > cp437
+}
+
+// neighbours returns the characters before and after the index of p.
+// A space is returned for a character beyond the start or end of p.
+func neighbours(p []byte, i int) (byte, byte) {
+	prev, next := byte(' '), byte(' ')
+	if i > 0 {
+		prev = p[i-1]
+	}
+	if i < len(p)-1 {
+		next = p[i+1]
+	}
+	return prev, next
+}
+
+// unassigned returns true if the character is not used by Windows-1252.
+func unassigned(char byte) bool {
+	switch char {
+	case 0x81, 0x8d, 0x8f, 0x90, 0x9d:
+		return true
+	}
+	return false
+}
+
+// punctuation returns true if the character is Windows-1252 typographic punctuation
+// or a symbol that is used in the context of its prev and next characters.
+func punctuation(char, prev, next byte) bool {
+	const (
+		euro        = 0x80 // €
+		ellipsis    = 0x85 // …
+		permille    = 0x89 // ‰
+		leftSingle  = 0x91 // ‘
+		rightSingle = 0x92 // ’
+		leftDouble  = 0x93 // “
+		rightDouble = 0x94 // ”
+		bullet      = 0x95 // •
+		enDash      = 0x96 // –
+		emDash      = 0x97 // —
+		trademark   = 0x99 // ™
+	)
+	opener := space(prev) || prev == '(' || prev == '['
+	switch char {
+	case euro:
+		return digit(prev) || digit(next)
+	case ellipsis:
+		return (letter(prev) || digit(prev) || closer(prev)) && (space(next) || closer(next))
+	case permille:
+		return digit(prev)
+	case leftSingle, leftDouble:
+		return opener && (letter(next) || digit(next))
+	case rightSingle:
+		// apostrophes, it’s, don’t and the groups’
+		return letter(prev) && (letter(next) || space(next) || closer(next))
+	case rightDouble:
+		return (letter(prev) || digit(prev) || closer(prev)) && (space(next) || closer(next))
+	case bullet:
+		return space(prev) && space(next)
+	case enDash:
+		return (space(prev) && space(next)) || (digit(prev) && digit(next))
+	case emDash:
+		return (space(prev) && space(next)) || (letter(prev) && letter(next))
+	case trademark:
+		return letter(prev) && (space(next) || closer(next))
+	}
+	return false
+}
+
+// letter returns true if the character is an ASCII letter.
+func letter(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
+// digit returns true if the character is an ASCII digit.
+func digit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+// space returns true if the character is an ASCII whitespace character.
+func space(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n' || char == '\r'
+}
+
+// closer returns true if the character is punctuation that ends a word or sentence.
+func closer(char byte) bool {
+	switch char {
+	case '.', ',', '!', '?', ';', ':', ')', ']', '"', '\'':
+		return true
+	}
+	return false
+}
+
+// runes matches the Unicode multi-byte characters.
+// If an unknown rune is encountered then the matching stops, as the encoding is
+// assumed to be a legacy 8-bit code page encoding, such as CP-437.
+func (x *examination) runes(p []byte) {
+	for i := 0; i < len(p); {
+		r, size := utf8.DecodeRune(p[i:])
+		if r == utf8.RuneError {
+			x.add(RuleInvalid, i, p[i:i+size], charmap.CodePage437)
+			return
+		}
+		if size > 1 {
+			x.add(RuleMultibyte, i, p[i:i+size], unicode.UTF8)
+		}
+		i += size
+	}
+}
+
+// legacy returns true if any of the characters or sequences are unique to the legacy code pages.
+func (x *examination) legacy() bool {
+	return x.tally[RuleControl]+x.tally[RuleUndefined]+x.tally[RuleSequence] > 0
+}
+
+// detection ranks the candidate encodings using the rule matches.
+func (x *examination) detection() Detection {
+	cp437 := x.tally[RuleControl] + x.tally[RuleUndefined] + x.tally[RuleSequence]
+	utf8s := x.tally[RuleMultibyte]
+	latin1 := x.tally[RuleExtended]
+	win1252 := x.tally[RulePunctuation]
+	scores := []Candidate{
+		{Encoding: charmap.CodePage437, Score: cp437 + x.tally[RuleAccent] + x.tally[RuleUnassigned] + x.tally[RuleDrawing]},
+		{Encoding: charmap.ISO8859_1, Score: latin1},
+		{Encoding: charmap.Windows1252, Score: win1252},
+		{Encoding: unicode.UTF8, Score: utf8s},
+	}
+	for _, cm := range nationals[1:] {
+		scores = append(scores, Candidate{Encoding: cm, Score: max(0, x.dosScores[cm])})
+	}
+	if x.wide != nil {
+		scores = append([]Candidate{{Encoding: x.wide, Score: int(x.size)}}, scores...)
+	}
+	if x.amiga() {
+		scores = append([]Candidate{{Encoding: x.amigaFont(), Score: x.tally[RuleAmiga] + x.tally[RuleAmigaFont]}}, scores...)
+	}
+	if x.home != nil {
+		scores = append([]Candidate{{Encoding: x.home, Score: x.tally[RulePETSCII] + x.tally[RuleATASCII]}}, scores...)
+	}
+	// The winner follows the precedence of the rules,
+	// a byte order mark or wide characters outrank everything,
+	// followed by the PETSCII and ATASCII characters of the home computers,
+	// then an Amiga font or the Amiga control sequences,
+	// any CP-437 characters or sequences outrank any Unicode multi-byte characters,
+	// unless the unused ASCII characters are better explained as Windows-1252 punctuation,
+	// or the extended characters read as words in a national MS-DOS code page,
+	// which also outranks ISO-8859-1 when the words are more plausible than Latin-1.
+	var winner encoding.Encoding = charmap.ISO8859_1
+	switch {
+	case x.wide != nil:
+		winner = x.wide
+	case x.home != nil:
+		winner = x.home
+	case x.amiga():
+		winner = x.amigaFont()
+	case x.legacy() && x.windows1252():
+		winner = charmap.Windows1252
+	case x.legacy():
+		winner = x.dos
+	case x.dos != charmap.CodePage437 && x.dosScores[x.dos] > x.latin1:
+		winner = x.dos
+	case utf8s > 0:
+		winner = unicode.UTF8
+	}
+	return Detection{
+		Candidates: rank(winner, scores),
+		Evidence:   x.evidence,
+		Tally:      x.tally,
+		Size:       x.size,
+	}
+}
+
+// rank returns the candidates sorted by confidence with the winner first.
+//
+// The winner always has a confidence of at least 0.5, which grows with the share
+// of its score against the other candidates. The remaining confidence is shared
+// among the other candidates in proportion to their scores, or equally when
+// none of them have scored.
+func rank(e encoding.Encoding, candidates []Candidate) []Candidate {
+	winner := -1
+	for i, c := range candidates {
+		if c.Encoding == e {
+			winner = i
+		}
+	}
+	if winner < 0 {
+		return nil
+	}
+	cs := make([]Candidate, len(candidates))
+	copy(cs, candidates)
+	win := float64(cs[winner].Score)
+	others := 0
+	for i, c := range cs {
+		cs[i].Name = EncodingName(c.Encoding)
+		if i != winner {
+			others += c.Score
+		}
+	}
+	const half = 0.5
+	confidence := 1.0
+	if len(cs) > 1 {
+		confidence = half + half*(win/(win+float64(others)+1))
+	}
+	remain := 1 - confidence
+	for i, c := range cs {
+		switch {
+		case i == winner:
+			cs[i].Confidence = confidence
+		case others > 0:
+			cs[i].Confidence = remain * float64(c.Score) / float64(others)
+		default:
+			cs[i].Confidence = remain / float64(len(cs)-1)
+		}
+	}
+	w := cs[winner]
+	cs = append(cs[:winner], cs[winner+1:]...)
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].Confidence > cs[j].Confidence
+	})
+	return append([]Candidate{w}, cs...)
+}
+
+// EncodingName returns the human readable name of the encoding,
+// or an empty string if the encoding is nil.
+func EncodingName(e encoding.Encoding) string {
+	if e == nil {
+		return ""
+	}
+	if s, ok := e.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", e)
+}
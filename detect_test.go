@@ -0,0 +1,132 @@
+package helper_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+	d, err := helper.Detect(nil)
+	require.ErrorIs(t, err, helper.ErrReader)
+	assert.Nil(t, d.Encoding())
+
+	d, err = helper.Detect(strings.NewReader("Hello world!"))
+	require.NoError(t, err)
+	assert.Equal(t, charmap.ISO8859_1, d.Encoding())
+	assert.Len(t, d.Candidates, 8)
+	assert.InDelta(t, 0.5, d.Candidates[0].Confidence, 0.001)
+	assert.Empty(t, d.Evidence)
+	assert.Equal(t, int64(12), d.Size)
+
+	d, err = helper.Detect(strings.NewReader("Hello ½ world ©"))
+	require.NoError(t, err)
+	assert.Equal(t, unicode.UTF8, d.Encoding())
+	assert.Equal(t, 2, d.Tally[helper.RuleMultibyte])
+	require.Len(t, d.Evidence, 2)
+	assert.Equal(t, int64(6), d.Evidence[0].Offset)
+	assert.Equal(t, []byte("½"), d.Evidence[0].Value)
+	assert.Equal(t, "UTF-8", d.Evidence[0].Supports)
+}
+
+func TestDetectEvidence(t *testing.T) {
+	t.Parallel()
+	p := []byte("Hi ")
+	p = append(p, 0x0d, 0x0e) // CP437 ♪ ♫
+	p = append(p, []byte(" there ")...)
+	p = append(p, 0xdb, 0xdb, 0xdb, 0xdb, 0xdb) // █████
+	d, err := helper.Detect(strings.NewReader(string(p)))
+	require.NoError(t, err)
+	assert.Equal(t, charmap.CodePage437, d.Encoding())
+	assert.Equal(t, "IBM Code Page 437", d.Candidates[0].Name)
+	assert.Equal(t, 1, d.Tally[helper.RuleControl])
+	assert.Equal(t, 1, d.Tally[helper.RuleSequence])
+	assert.Equal(t, 5, d.Tally[helper.RuleExtended])
+	assert.Equal(t, 1, d.Tally[helper.RuleInvalid])
+
+	var seq helper.Evidence
+	for _, e := range d.Evidence {
+		if e.Rule == helper.RuleSequence {
+			seq = e
+		}
+	}
+	assert.Equal(t, int64(12), seq.Offset)
+	assert.Len(t, seq.Value, 5)
+
+	sum := 0.0
+	for i, c := range d.Candidates {
+		sum += c.Confidence
+		if i > 0 {
+			assert.LessOrEqual(t, c.Confidence, d.Candidates[i-1].Confidence)
+		}
+	}
+	assert.InDelta(t, 1.0, sum, 0.001)
+}
+
+func TestDetectLimit(t *testing.T) {
+	t.Parallel()
+	s := strings.Repeat("\x01", helper.EvidenceLimit*2)
+	d, err := helper.Detect(strings.NewReader(s))
+	require.NoError(t, err)
+	assert.Equal(t, charmap.CodePage437, d.Encoding())
+	assert.Equal(t, helper.EvidenceLimit*2, d.Tally[helper.RuleControl])
+	assert.Len(t, d.Evidence, helper.EvidenceLimit)
+}
+
+func TestEncodingName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "", helper.EncodingName(nil))
+	assert.Equal(t, "UTF-8", helper.EncodingName(unicode.UTF8))
+	assert.Equal(t, "ISO 8859-1", helper.EncodingName(charmap.ISO8859_1))
+}
+
+func TestDetectWindows1252(t *testing.T) {
+	t.Parallel()
+	r, err := os.Open("testdata/WIN1252.TXT")
+	require.NoError(t, err)
+	defer r.Close()
+	d, err := helper.Detect(r)
+	require.NoError(t, err)
+	assert.Equal(t, charmap.Windows1252, d.Encoding())
+	assert.Positive(t, d.Tally[helper.RulePunctuation])
+
+	r, err = os.Open("testdata/INFINITY.NFO")
+	require.NoError(t, err)
+	defer r.Close()
+	d, err = helper.Detect(r)
+	require.NoError(t, err)
+	assert.Equal(t, charmap.CodePage437, d.Encoding())
+	assert.Positive(t, d.Tally[helper.RuleDrawing])
+	assert.Positive(t, d.Tally[helper.RuleAccent])
+
+	tests := []struct {
+		name   string
+		text   []byte
+		expect encoding.Encoding
+	}{
+		{"apostrophe", []byte("don\x92t stop"), charmap.Windows1252},
+		{"quotes", []byte("the \x93best\x94 release"), charmap.Windows1252},
+		{"dash", []byte("one \x97 two"), charmap.Windows1252},
+		{"euro", []byte("only \x805"), charmap.Windows1252},
+		{"cp437 umlaut", []byte("Gr\x81\xe1e aus K\x94ln"), charmap.CodePage437},
+		{"cp437 accent", []byte("caf\x82 cr\x8ame"), charmap.CodePage437},
+		{"unassigned", []byte("\x90 \x9d"), charmap.CodePage437},
+		{"utf-8 emoji", []byte("Hello world 👾!!!"), unicode.UTF8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			e := helper.Determine(bytes.NewReader(tt.text))
+			assert.Equal(t, tt.expect, e, "wanted %s but got %s", tt.expect, e)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package helper
+
+// Package file detector.go contains the memory bounded, streaming encoding detection.
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+)
+
+// SampleLimit is the default number of bytes that a Detector keeps as its sample.
+const SampleLimit = 64 * 1024
+
+// Detector is an io.Writer that detects the encoding of the text that is written to it.
+// Only the first Limit bytes are kept as a sample in memory and the remainder is discarded,
+// so a Detector runs in constant memory regardless of the size of the text.
+//
+// For text that is no larger than the Limit, the Detector always gives the same result
+// as Determine and Detect.
+type Detector struct {
+	limit   int
+	sample  []byte
+	written int64
+}
+
+// NewDetector returns a Detector that samples up to limit bytes.
+// If limit is zero or less, then the SampleLimit is used.
+func NewDetector(limit int) *Detector {
+	if limit <= 0 {
+		limit = SampleLimit
+	}
+	return &Detector{
+		limit:  limit,
+		sample: make([]byte, 0, min(limit, SampleLimit)),
+	}
+}
+
+// Write adds p to the sample of the Detector until its limit is reached.
+// It always returns len(p) and a nil error.
+func (d *Detector) Write(p []byte) (int, error) {
+	d.written += int64(len(p))
+	if remain := d.limit - len(d.sample); remain > 0 {
+		d.sample = append(d.sample, p[:min(remain, len(p))]...)
+	}
+	return len(p), nil
+}
+
+// Reset discards the sample so the Detector can be reused.
+func (d *Detector) Reset() {
+	d.sample = d.sample[:0]
+	d.written = 0
+}
+
+// Truncated returns true if more bytes were written than the sample limit.
+func (d *Detector) Truncated() bool {
+	return d.written > int64(len(d.sample))
+}
+
+// Written returns the total number of bytes written to the Detector.
+func (d *Detector) Written() int64 {
+	return d.written
+}
+
+// Detection returns the detection of the sampled text.
+func (d *Detector) Detection() Detection {
+	p := d.sample
+	if d.Truncated() {
+		p = trimRune(p)
+	}
+	return detect(p)
+}
+
+// Encoding returns the most likely encoding of the sampled text.
+func (d *Detector) Encoding() encoding.Encoding {
+	return d.Detection().Encoding()
+}
+
+// DetectSample reads up to limit bytes of the plain text and returns the detection of the sample.
+// If limit is zero or less, then the SampleLimit is used.
+// Unlike Detect, the memory use is bounded by the limit no matter the size of the text.
+func DetectSample(reader io.Reader, limit int) (Detection, error) {
+	if reader == nil {
+		return Detection{}, ErrReader
+	}
+	d := NewDetector(limit)
+	// read an extra byte so the detector knows if the text was truncated
+	if _, err := io.Copy(d, io.LimitReader(reader, int64(d.limit)+1)); err != nil {
+		return Detection{}, fmt.Errorf("detect sample copy %w", err)
+	}
+	return d.Detection(), nil
+}
+
+// trimRune removes an incomplete UTF-8 encoded character from the end of p,
+// which happens when a text is truncated in the middle of a multi-byte character.
+func trimRune(p []byte) []byte {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(p[i]) {
+			continue
+		}
+		if !utf8.FullRune(p[i:]) {
+			return p[:i]
+		}
+		break
+	}
+	return p
+}
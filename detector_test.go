@@ -0,0 +1,109 @@
+package helper_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestDetector(t *testing.T) {
+	t.Parallel()
+	for _, name := range []string{"testdata/INFINITY.NFO", "testdata/WIN1252.TXT", "testdata/PKZ80A1.TXT"} {
+		p, err := os.ReadFile(name)
+		require.NoError(t, err)
+		d := helper.NewDetector(0)
+		n, err := io.Copy(d, bytes.NewReader(p))
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(p)), n)
+		assert.False(t, d.Truncated())
+		assert.Equal(t, helper.Determine(bytes.NewReader(p)), d.Encoding(), name)
+		x, err := helper.Detect(bytes.NewReader(p))
+		require.NoError(t, err)
+		assert.Equal(t, x, d.Detection(), name)
+	}
+}
+
+func TestDetectorLimit(t *testing.T) {
+	t.Parallel()
+	d := helper.NewDetector(10)
+	n, err := d.Write([]byte("Hello world, "))
+	require.NoError(t, err)
+	assert.Equal(t, 13, n)
+	_, _ = d.Write([]byte{0xdb, 0xdb, 0xdb, 0xdb})
+	assert.True(t, d.Truncated())
+	assert.Equal(t, int64(17), d.Written())
+	assert.Equal(t, charmap.ISO8859_1, d.Encoding())
+	assert.Equal(t, int64(10), d.Detection().Size)
+
+	d.Reset()
+	assert.Equal(t, int64(0), d.Written())
+	// a multi-byte character split by the limit must not spoil the UTF-8 detection
+	_, _ = d.Write([]byte("Hi ½ wo👾ld"))
+	assert.True(t, d.Truncated())
+	assert.Equal(t, unicode.UTF8, d.Encoding())
+	_, _ = d.Write([]byte("more text"))
+	assert.Equal(t, unicode.UTF8, d.Encoding())
+}
+
+func TestDetectSample(t *testing.T) {
+	t.Parallel()
+	_, err := helper.DetectSample(nil, 0)
+	require.ErrorIs(t, err, helper.ErrReader)
+
+	s := strings.Repeat("Hello world! ", 100) + "\x0e"
+	d, err := helper.DetectSample(strings.NewReader(s), 16)
+	require.NoError(t, err)
+	assert.Equal(t, charmap.ISO8859_1, d.Encoding())
+	d, err = helper.DetectSample(strings.NewReader(s), 0)
+	require.NoError(t, err)
+	assert.Equal(t, charmap.CodePage437, d.Encoding())
+}
+
+func benchmarkText(b *testing.B) []byte {
+	b.Helper()
+	p, err := os.ReadFile("testdata/INFINITY.NFO")
+	require.NoError(b, err)
+	const size = 8 * 1024 * 1024
+	return bytes.Repeat(p, size/len(p))
+}
+
+func BenchmarkDetermine(b *testing.B) {
+	p := benchmarkText(b)
+	b.SetBytes(int64(len(p)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		_ = helper.Determine(bytes.NewReader(p))
+	}
+}
+
+func BenchmarkDetector(b *testing.B) {
+	p := benchmarkText(b)
+	d := helper.NewDetector(0)
+	b.SetBytes(int64(len(p)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		d.Reset()
+		_, _ = io.Copy(d, bytes.NewReader(p))
+		_ = d.Encoding()
+	}
+}
+
+func BenchmarkDetectSample(b *testing.B) {
+	p := benchmarkText(b)
+	b.SetBytes(int64(len(p)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		_, _ = helper.DetectSample(bytes.NewReader(p), 0)
+	}
+}
@@ -0,0 +1,297 @@
+package helper
+
+// Package file encode.go contains the encoder of UTF-8 text to the legacy 8-bit code pages,
+// and the negotiation of the charset using the Accept-Charset header.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	ErrCharset  = errors.New("no charset is acceptable")
+	ErrEncode   = errors.New("rune is not in the character set")
+	ErrEncoding = errors.New("encoding is not an 8-bit character set")
+)
+
+// Fallback is the handling of the runes that are not in the character set of a legacy encoding.
+type Fallback int
+
+const (
+	FallbackError         Fallback = iota // FallbackError stops the encoding with an EncodeError.
+	FallbackReplace                       // FallbackReplace uses the ? replacement character.
+	FallbackTransliterate                 // FallbackTransliterate uses the nearest characters, or the ? replacement character.
+)
+
+// replacement is the character used by the FallbackReplace and FallbackTransliterate fallbacks.
+const replacement = '?'
+
+// EncodeError is a rune of the UTF-8 text that is not in the character set of the encoding.
+type EncodeError struct {
+	Rune   rune  // Rune is the rune that could not be encoded, or utf8.RuneError for invalid UTF-8.
+	Offset int64 // Offset is the position of the rune in the UTF-8 text.
+}
+
+// Error implements the error interface.
+func (e *EncodeError) Error() string {
+	return fmt.Sprintf("%s: %U at offset %d", ErrEncode, e.Rune, e.Offset)
+}
+
+// Unwrap returns ErrEncode.
+func (e *EncodeError) Unwrap() error {
+	return ErrEncode
+}
+
+// runeEncoder is an 8-bit character set, such as a charmap.Charmap or a codepage.Charmap.
+type runeEncoder interface {
+	EncodeRune(r rune) (byte, bool)
+}
+
+// NewLegacyWriter returns a writer that encodes the UTF-8 text written to it
+// using the 8-bit character set of the encoding, such as charmap.CodePage437,
+// charmap.ISO8859_1 or charmap.Windows1252.
+// The runes that are not in the character set are handled by the fallback.
+// The writer must be closed to flush any incomplete rune.
+func NewLegacyWriter(w io.Writer, e encoding.Encoding, fallback Fallback) (io.WriteCloser, error) {
+	if w == nil {
+		return nil, ErrWriter
+	}
+	t, err := legacyTransformer(e, fallback)
+	if err != nil {
+		return nil, err
+	}
+	return transform.NewWriter(w, t), nil
+}
+
+// EncodeLegacy returns the UTF-8 text of p encoded using the 8-bit character set of the encoding.
+// The runes that are not in the character set are handled by the fallback,
+// and using FallbackError, the returned error is an *EncodeError.
+func EncodeLegacy(p []byte, e encoding.Encoding, fallback Fallback) ([]byte, error) {
+	t, err := legacyTransformer(e, fallback)
+	if err != nil {
+		return nil, err
+	}
+	b, _, err := transform.Bytes(t, p)
+	if err != nil {
+		return nil, fmt.Errorf("encode legacy %w", err)
+	}
+	return b, nil
+}
+
+// legacyTransformer returns a transformer that encodes UTF-8 text to the encoding.
+func legacyTransformer(e encoding.Encoding, fallback Fallback) (*legacy, error) {
+	enc, ok := e.(runeEncoder)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrEncoding, e)
+	}
+	return &legacy{enc: enc, fallback: fallback}, nil
+}
+
+// legacy is a transformer that encodes UTF-8 text to an 8-bit character set.
+type legacy struct {
+	enc      runeEncoder
+	fallback Fallback
+	offset   int64 // offset is the number of UTF-8 bytes transformed by the previous calls
+}
+
+// Reset implements the transform.Transformer interface.
+func (l *legacy) Reset() {
+	l.offset = 0
+}
+
+// Transform implements the transform.Transformer interface.
+func (l *legacy) Transform(dst, src []byte, atEOF bool) (int, int, error) {
+	nDst, nSrc := 0, 0
+	done := func(err error) (int, int, error) {
+		l.offset += int64(nSrc)
+		return nDst, nSrc, err
+	}
+	for nSrc < len(src) {
+		r, size := rune(src[nSrc]), 1
+		if r >= utf8.RuneSelf {
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return done(transform.ErrShortSrc)
+			}
+			r, size = utf8.DecodeRune(src[nSrc:])
+		}
+		var p []byte
+		b, ok := l.enc.EncodeRune(r)
+		switch {
+		case ok && (r != utf8.RuneError || size > 1):
+			p = []byte{b}
+		case l.fallback == FallbackReplace:
+			p = []byte{replacement}
+		case l.fallback == FallbackTransliterate:
+			p = transliterate(l.enc, r)
+		default:
+			return done(&EncodeError{Rune: r, Offset: l.offset + int64(nSrc)})
+		}
+		if nDst+len(p) > len(dst) {
+			return done(transform.ErrShortDst)
+		}
+		nDst += copy(dst[nDst:], p)
+		nSrc += size
+	}
+	return done(nil)
+}
+
+// substitutes are the nearest characters of the runes that are missing from some legacy character sets,
+// in order of preference.
+var substitutes = map[rune][]string{
+	'‘': {"'"}, '’': {"'"}, '‚': {","}, '‛': {"'"}, '′': {"'"},
+	'“': {`"`}, '”': {`"`}, '„': {`"`}, '‟': {`"`}, '″': {`"`},
+	'‹': {"<"}, '›': {">"}, '«': {"<<"}, '»': {">>"},
+	'‐': {"-"}, '‑': {"-"}, '‒': {"-"}, '–': {"-"}, '—': {"-"}, '―': {"-"}, '−': {"-"},
+	'…': {"..."}, '•': {"∙", "·", "*"}, '·': {"∙", "."}, '∙': {"·", "."},
+	'€': {"EUR"}, '£': {"GBP"}, '¥': {"JPY"}, '¢': {"c"}, '™': {"TM"}, '©': {"(C)"}, '®': {"(R)"},
+	'×': {"x"}, '÷': {"/"}, '±': {"+/-"}, '≤': {"<="}, '≥': {">="}, '≠': {"!="}, '≈': {"~"},
+	'←': {"<-"}, '→': {"->"}, '↑': {"^"}, '↓': {"v"}, '↔': {"<->"},
+	'Œ': {"OE"}, 'œ': {"oe"}, 'Æ': {"AE"}, 'æ': {"ae"}, 'ß': {"ss"},
+	'Ø': {"O"}, 'ø': {"o"}, 'Ł': {"L"}, 'ł': {"l"}, 'Đ': {"D"}, 'đ': {"d"},
+	'Þ': {"Th"}, 'þ': {"th"}, 'Ð': {"D"}, 'ð': {"d"},
+	'¡': {"!"}, '¿': {"?"}, '°': {"o"}, 'º': {"o"}, 'ª': {"a"},
+	'\u00a0': {" "}, '\u2002': {" "}, '\u2003': {" "}, '\u2009': {" "}, '\u200b': {""}, '\u00ad': {"-", ""},
+	// the box drawing and block characters use the CP-437 equivalents or ASCII art
+	'─': {"-"}, '━': {"─", "-"}, '═': {"─", "="},
+	'│': {"|"}, '┃': {"│", "|"}, '║': {"│", "|"},
+	'┌': {"+"}, '┐': {"+"}, '└': {"+"}, '┘': {"+"}, '├': {"+"}, '┤': {"+"}, '┬': {"+"}, '┴': {"+"}, '┼': {"+"},
+	'╭': {"┌", "+"}, '╮': {"┐", "+"}, '╰': {"└", "+"}, '╯': {"┘", "+"},
+	'┏': {"┌", "+"}, '┓': {"┐", "+"}, '┗': {"└", "+"}, '┛': {"┘", "+"},
+	'╔': {"┌", "+"}, '╗': {"┐", "+"}, '╚': {"└", "+"}, '╝': {"┘", "+"},
+	'╠': {"├", "+"}, '╣': {"┤", "+"}, '╦': {"┬", "+"}, '╩': {"┴", "+"}, '╬': {"┼", "+"},
+	'█': {"#"}, '▓': {"█", "#"}, '▒': {"▓", "#"}, '░': {"▒", ":"},
+	'▀': {"█", "\""}, '▄': {"█", "_"}, '▌': {"█", "|"}, '▐': {"█", "|"}, '■': {"#"},
+}
+
+// transliterate returns the nearest characters of the rune that are in the character set,
+// or the replacement character when there are none.
+// The substitutes are tried first, followed by the rune without its accents or
+// in its compatibility form, so a ligature such as ﬁ becomes fi.
+func transliterate(enc runeEncoder, r rune) []byte {
+	for _, s := range substitutes[r] {
+		if p, ok := encodeString(enc, s); ok {
+			return p
+		}
+	}
+	if r != utf8.RuneError {
+		s := strings.Map(func(r rune) rune {
+			if unicode.Is(unicode.Mn, r) {
+				return -1
+			}
+			return r
+		}, norm.NFKD.String(string(r)))
+		if p, ok := encodeString(enc, s); ok && s != "" {
+			return p
+		}
+	}
+	return []byte{replacement}
+}
+
+// encodeString returns the string encoded using the character set,
+// or false if any of the runes are not in the character set.
+func encodeString(enc runeEncoder, s string) ([]byte, bool) {
+	p := make([]byte, 0, len(s))
+	for _, r := range s {
+		b, ok := enc.EncodeRune(r)
+		if !ok {
+			return nil, false
+		}
+		p = append(p, b)
+	}
+	return p, true
+}
+
+// NegotiateCharset returns the offered encoding and its preferred MIME charset name that best matches the
+// value of an Accept-Charset HTTP request header. For example, an offer of charmap.CodePage437,
+// charmap.Windows1252 and unicode.UTF8 returns "IBM437" for an "ibm437, utf-8;q=0.8" header.
+//
+// The offers are the encodings that can be served in order of preference,
+// which is used to break any ties of the header quality values.
+// An empty header accepts any offer, so it returns the first.
+// Offers without a MIME charset name are ignored.
+// If none of the offers are acceptable, ErrCharset is returned.
+func NegotiateCharset(header string, offers ...encoding.Encoding) (encoding.Encoding, string, error) {
+	accept := acceptCharsets(header)
+	var (
+		best    encoding.Encoding
+		name    string
+		quality float64
+	)
+	for _, offer := range offers {
+		charset, err := ianaindex.MIME.Name(offer)
+		if err != nil || charset == "" {
+			continue
+		}
+		q, ok := 1.0, true
+		if header != "" {
+			q, ok = accept.quality(offer)
+		}
+		if ok && q > quality {
+			best, name, quality = offer, charset, q
+		}
+	}
+	if best == nil {
+		return nil, "", fmt.Errorf("%w: %q", ErrCharset, header)
+	}
+	return best, name, nil
+}
+
+// accepts are the quality values of the charsets of an Accept-Charset header.
+type accepts struct {
+	charsets map[encoding.Encoding]float64
+	wildcard float64 // wildcard is the quality of the * charset, or -1 when it is not used
+}
+
+// acceptCharsets parses the value of an Accept-Charset header,
+// ignoring any unknown charsets and invalid quality values.
+func acceptCharsets(header string) accepts {
+	a := accepts{
+		charsets: make(map[encoding.Encoding]float64),
+		wildcard: -1,
+	}
+	for _, field := range strings.Split(header, ",") {
+		charset, params, _ := strings.Cut(field, ";")
+		charset = strings.TrimSpace(charset)
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, val, _ := strings.Cut(param, "=")
+			if !strings.EqualFold(strings.TrimSpace(key), "q") {
+				continue
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+			if err != nil || f < 0 || f > 1 {
+				f = 0
+			}
+			q = f
+		}
+		if charset == "*" {
+			a.wildcard = q
+			continue
+		}
+		e, err := ianaindex.IANA.Encoding(charset)
+		if err != nil || e == nil {
+			continue
+		}
+		a.charsets[e] = q
+	}
+	return a
+}
+
+// quality returns the quality value of the encoding and true if the encoding is acceptable.
+func (a accepts) quality(e encoding.Encoding) (float64, bool) {
+	q, ok := a.charsets[e]
+	if !ok {
+		q = a.wildcard
+	}
+	return q, q > 0
+}
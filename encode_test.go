@@ -0,0 +1,142 @@
+package helper_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/codepage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func ExampleEncodeLegacy() {
+	p, _ := helper.EncodeLegacy([]byte("“Café” — ☺"), charmap.CodePage437, helper.FallbackTransliterate)
+	fmt.Printf("%q\n", p)
+	// Output:
+	// "\"Caf\x82\" - ?"
+}
+
+func ExampleNegotiateCharset() {
+	_, name, _ := helper.NegotiateCharset("utf-8;q=0.8, ibm437",
+		unicode.UTF8, charmap.CodePage437, charmap.Windows1252)
+	fmt.Println(name)
+	// Output:
+	// IBM437
+}
+
+func TestEncodeLegacy(t *testing.T) {
+	t.Parallel()
+	const s = "Café ░▒▓█ ─┼─ “quote” … 5€"
+	p, err := helper.EncodeLegacy([]byte(s), charmap.CodePage437, helper.FallbackError)
+	require.Error(t, err)
+	assert.Nil(t, p)
+	require.ErrorIs(t, err, helper.ErrEncode)
+	var ee *helper.EncodeError
+	require.ErrorAs(t, err, &ee)
+	assert.Equal(t, '“', ee.Rune)
+	assert.Equal(t, int64(bytes.Index([]byte(s), []byte("“"))), ee.Offset)
+
+	p, err = helper.EncodeLegacy([]byte(s), charmap.CodePage437, helper.FallbackReplace)
+	require.NoError(t, err)
+	assert.Equal(t, "Caf\x82 \xb0\xb1\xb2\xdb \xc4\xc5\xc4 ?quote? ? 5?", string(p))
+
+	p, err = helper.EncodeLegacy([]byte(s), charmap.CodePage437, helper.FallbackTransliterate)
+	require.NoError(t, err)
+	assert.Equal(t, "Caf\x82 \xb0\xb1\xb2\xdb \xc4\xc5\xc4 \"quote\" ... 5EUR", string(p))
+
+	p, err = helper.EncodeLegacy([]byte(s), charmap.ISO8859_1, helper.FallbackTransliterate)
+	require.NoError(t, err)
+	assert.Equal(t, "Caf\xe9 :### -+- \"quote\" ... 5EUR", string(p))
+
+	p, err = helper.EncodeLegacy([]byte(s), charmap.Windows1252, helper.FallbackTransliterate)
+	require.NoError(t, err)
+	assert.Equal(t, "Caf\xe9 :### -+- \x93quote\x94 \x85 5\x80", string(p))
+}
+
+func TestEncodeLegacyTransliterate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		s, expect string
+	}{
+		{"Łódź", "L\xa2dz"},
+		{"ﬁne", "fine"},
+		{"Ａ", "A"},
+		{"╭─╮", "\xda\xc4\xbf"},
+		{"→", "->"},
+		{"漢", "?"},
+		{"a\xffb", "a?b"},
+	}
+	for _, tt := range tests {
+		p, err := helper.EncodeLegacy([]byte(tt.s), charmap.CodePage437, helper.FallbackTransliterate)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expect, string(p), tt.s)
+	}
+	// the glyph encoding keeps the pictographs of the control characters
+	p, err := helper.EncodeLegacy([]byte("☺ →\r\n"), codepage.CodePage437(codepage.KeepNewlines), helper.FallbackError)
+	require.NoError(t, err)
+	assert.Equal(t, "\x01 \x1a\r\n", string(p))
+
+	_, err = helper.EncodeLegacy([]byte("hi"), unicode.UTF8, helper.FallbackError)
+	require.ErrorIs(t, err, helper.ErrEncoding)
+}
+
+func TestNewLegacyWriter(t *testing.T) {
+	t.Parallel()
+	_, err := helper.NewLegacyWriter(nil, charmap.CodePage437, helper.FallbackError)
+	require.ErrorIs(t, err, helper.ErrWriter)
+
+	buf := new(bytes.Buffer)
+	w, err := helper.NewLegacyWriter(buf, charmap.CodePage437, helper.FallbackError)
+	require.NoError(t, err)
+	// the rune é is split between writes
+	s := []byte("Café crème")
+	for i := range s {
+		_, err = w.Write(s[i : i+1])
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	assert.Equal(t, "Caf\x82 cr\x8ame", buf.String())
+
+	buf.Reset()
+	w, err = helper.NewLegacyWriter(buf, charmap.ISO8859_1, helper.FallbackError)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("line one\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("line ☃\n"))
+	var ee *helper.EncodeError
+	require.True(t, errors.As(err, &ee), err)
+	assert.Equal(t, int64(len("line one\nline ")), ee.Offset)
+}
+
+func TestNegotiateCharset(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		header string
+		expect string
+	}{
+		{"", "IBM437"},
+		{"utf-8", "UTF-8"},
+		{"iso-8859-1;q=0.5, windows-1252;q=0.7", "windows-1252"},
+		{"latin1", "ISO-8859-1"},
+		{"cp437;q=0.1, utf-8;q=0.1", "IBM437"},
+		{"*", "IBM437"},
+		{"ibm437;q=0, *;q=0.5", "UTF-8"},
+		{"UTF-8 ; Q=0.9, unknown-charset", "UTF-8"},
+	}
+	for _, tt := range tests {
+		e, name, err := helper.NegotiateCharset(tt.header,
+			charmap.CodePage437, unicode.UTF8, charmap.Windows1252, charmap.ISO8859_1)
+		require.NoError(t, err, tt.header)
+		assert.Equal(t, tt.expect, name, tt.header)
+		assert.NotNil(t, e)
+	}
+	_, _, err := helper.NegotiateCharset("koi8-r, utf-16", charmap.CodePage437, unicode.UTF8)
+	require.ErrorIs(t, err, helper.ErrCharset)
+	_, _, err = helper.NegotiateCharset("")
+	require.ErrorIs(t, err, helper.ErrCharset)
+}
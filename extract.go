@@ -0,0 +1,382 @@
+package helper
+
+// Package file extract.go contains the safe extraction of zip, tar and gzip archives.
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Defacto2/helper/pkzip"
+)
+
+var (
+	ErrArchive   = errors.New("archive format is not supported")
+	ErrDuplicate = errors.New("archive entry name is a duplicate")
+	ErrLimit     = errors.New("archive exceeds the extraction limits")
+	ErrUnsafe    = errors.New("archive entry path is unsafe")
+)
+
+const (
+	ExtractFiles       = 10000   // ExtractFiles is the default maximum number of entries to extract.
+	ExtractSize  int64 = 1 << 30 // ExtractSize is the default maximum total size in bytes of the extracted files.
+)
+
+// ExtractOptions are the limits of an extraction, which guard against decompression bombs.
+type ExtractOptions struct {
+	// Files is the maximum number of entries, or ExtractFiles when zero.
+	Files int
+	// Size is the maximum total size in bytes of the extracted files, or ExtractSize when zero.
+	Size int64
+}
+
+// Extracted is an entry of an archive that was written to the destination directory.
+type Extracted struct {
+	Name     string      // Name is the cleaned, slash-separated name of the entry in the archive.
+	Path     string      // Path is the absolute path of the extracted entry.
+	Size     int64       // Size is the number of bytes written, which is zero for directories and links.
+	Type     fs.FileMode // Type is fs.ModeDir for a directory, fs.ModeSymlink for a link, or zero for a file.
+	Link     string      // Link is the target of a symbolic link.
+	Modified time.Time   // Modified is the modification time stored in the archive, which can be zero.
+}
+
+// Extract extracts the zip, tar, tar.gz or gzip archive of the named src file
+// into the destination directory created by MkContent.
+// It returns the destination directory and every extracted entry, see ExtractDir.
+func Extract(src string, opts ExtractOptions) (string, []Extracted, error) {
+	dst, err := MkContent(src)
+	if err != nil {
+		return "", nil, fmt.Errorf("extract %w", err)
+	}
+	entries, err := ExtractDir(src, dst, opts)
+	return dst, entries, err
+}
+
+// ExtractDir extracts the zip, tar, tar.gz or gzip archive of the named src file
+// into the dst directory and returns every extracted entry in the order of the archive.
+// The format is detected using the content of the file, and the zip archives can use
+// the legacy PKZIP compression methods, while their CP-437 names are decoded to UTF-8.
+//
+// The extraction stops with an error when an entry name is absolute or outside of dst (ErrUnsafe),
+// when a symbolic link target is absolute or uses a ".." parent element (ErrUnsafe),
+// when an entry would be written through a symbolic link (ErrUnsafe),
+// when a name is used more than once, ignoring case (ErrDuplicate),
+// or when there are too many entries or the files are too large (ErrLimit).
+// On error, the entries that were already extracted are returned, so they can be removed.
+//
+// Files use the WriteWriteRead mode and directories use DirWriteReadRead,
+// while the other tar entry types, such as devices and named pipes, are skipped.
+// Any existing files of dst with the same names as the entries are replaced.
+func ExtractDir(src, dst string, opts ExtractOptions) ([]Extracted, error) {
+	st, err := os.Stat(dst)
+	if err != nil {
+		return nil, fmt.Errorf("extract dir os.stat %w", err)
+	}
+	if !st.IsDir() {
+		return nil, fmt.Errorf("extract dir %w: %s", ErrDirPath, dst)
+	}
+	root, err := filepath.Abs(dst)
+	if err != nil {
+		return nil, fmt.Errorf("extract dir %w", err)
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, fmt.Errorf("extract dir os.open %w", err)
+	}
+	defer f.Close()
+	fst, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("extract dir file.stat %w", err)
+	}
+	x := extractor{
+		root:  root,
+		files: opts.Files,
+		size:  opts.Size,
+		names: make(map[string]fs.FileMode),
+	}
+	if x.files <= 0 {
+		x.files = ExtractFiles
+	}
+	if x.size <= 0 {
+		x.size = ExtractSize
+	}
+	head := make([]byte, tarMagic+len("ustar"))
+	n, _ := f.ReadAt(head, 0)
+	head = head[:n]
+	switch {
+	case bytes.HasPrefix(head, []byte("\x1f\x8b")):
+		err = x.gzip(f, filepath.Base(src))
+	case tarHeader(head):
+		err = x.tar(f)
+	default:
+		err = x.zip(f, fst.Size())
+	}
+	if err != nil {
+		return x.entries, fmt.Errorf("extract dir %s %w", filepath.Base(src), err)
+	}
+	return x.entries, nil
+}
+
+// tarMagic is the offset of the "ustar" magic of a tar header.
+const tarMagic = 257
+
+// tarHeader returns true if p starts with a POSIX or GNU tar header.
+func tarHeader(p []byte) bool {
+	return len(p) >= tarMagic+len("ustar") && string(p[tarMagic:tarMagic+len("ustar")]) == "ustar"
+}
+
+// extractor is the state of an extraction.
+type extractor struct {
+	root    string                 // root is the absolute destination directory
+	files   int                    // files is the maximum number of entries
+	size    int64                  // size is the remaining number of bytes that can be written
+	names   map[string]fs.FileMode // names are the lower case names and types of the extracted entries
+	entries []Extracted            // entries are the extracted entries
+}
+
+// zip extracts the entries of a zip archive.
+func (x *extractor) zip(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrArchive, err)
+	}
+	for _, f := range zr.File {
+		name := strings.ReplaceAll(pkzip.Name(f), `\`, "/")
+		switch {
+		case f.FileInfo().IsDir():
+			err = x.dir(name, f.Modified)
+		case f.Mode()&fs.ModeSymlink != 0:
+			err = x.zipLink(f, name)
+		default:
+			err = x.zipFile(f, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// zipFile extracts a file of a zip archive.
+func (x *extractor) zipFile(f *zip.File, name string) error {
+	if f.UncompressedSize64 > uint64(x.size) {
+		return fmt.Errorf("%w: %s is %d bytes", ErrLimit, name, f.UncompressedSize64)
+	}
+	rc, err := pkzip.Open(f)
+	if err != nil {
+		return fmt.Errorf("%s %w", name, err)
+	}
+	defer rc.Close()
+	return x.file(name, rc, f.Modified)
+}
+
+// zipLink extracts a symbolic link of a zip archive, which stores the target as the content.
+func (x *extractor) zipLink(f *zip.File, name string) error {
+	const maxLen = 4096
+	rc, err := pkzip.Open(f)
+	if err != nil {
+		return fmt.Errorf("%s %w", name, err)
+	}
+	defer rc.Close()
+	target, err := io.ReadAll(io.LimitReader(rc, maxLen))
+	if err != nil {
+		return fmt.Errorf("%s %w", name, err)
+	}
+	return x.link(name, string(target), f.Modified)
+}
+
+// tar extracts the entries of a tar archive.
+func (x *extractor) tar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrArchive, err)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.dir(hdr.Name, hdr.ModTime)
+		case tar.TypeSymlink:
+			err = x.link(hdr.Name, hdr.Linkname, hdr.ModTime)
+		case tar.TypeLink:
+			err = x.hardLink(hdr.Name, hdr.Linkname, hdr.ModTime)
+		case tar.TypeReg:
+			if hdr.Size > x.size {
+				return fmt.Errorf("%w: %s is %d bytes", ErrLimit, hdr.Name, hdr.Size)
+			}
+			err = x.file(hdr.Name, tr, hdr.ModTime)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// gzip extracts a gzip compressed tar archive or a single gzip compressed file.
+// The name of the file is stored in the gzip header, otherwise it is the archive name without the extension.
+func (x *extractor) gzip(r io.Reader, archive string) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrArchive, err)
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+	if head, _ := br.Peek(tarMagic + len("ustar")); tarHeader(head) {
+		return x.tar(br)
+	}
+	name := path.Base(strings.ReplaceAll(zr.Name, `\`, "/"))
+	if zr.Name == "" || name == "." || name == ".." || name == "/" {
+		name = strings.TrimSuffix(archive, filepath.Ext(archive))
+	}
+	return x.file(name, br, zr.ModTime)
+}
+
+// entry validates the name of an entry of the type and returns its cleaned name and destination path.
+func (x *extractor) entry(name string, typ fs.FileMode) (string, string, error) {
+	if len(x.entries) >= x.files {
+		return "", "", fmt.Errorf("%w: more than %d entries", ErrLimit, x.files)
+	}
+	clean, err := localName(name)
+	if err != nil {
+		return "", "", err
+	}
+	key := strings.ToLower(clean)
+	if t, ok := x.names[key]; ok && (!t.IsDir() || !typ.IsDir()) {
+		return "", "", fmt.Errorf("%w: %s", ErrDuplicate, name)
+	}
+	for dir := path.Dir(key); dir != "."; dir = path.Dir(dir) {
+		if x.names[dir]&fs.ModeSymlink != 0 {
+			return "", "", fmt.Errorf("%w: %s is in a symbolic link", ErrUnsafe, name)
+		}
+	}
+	x.names[key] = typ
+	dst := filepath.Join(x.root, filepath.FromSlash(clean))
+	if err := os.MkdirAll(filepath.Dir(dst), DirWriteReadRead); err != nil {
+		return "", "", fmt.Errorf("%s %w", name, err)
+	}
+	if typ.IsDir() {
+		return clean, dst, nil
+	}
+	// replace any existing file, while never following an existing symbolic link
+	if st, err := os.Lstat(dst); err == nil && !st.IsDir() {
+		if err := os.Remove(dst); err != nil {
+			return "", "", fmt.Errorf("%s %w", name, err)
+		}
+	}
+	return clean, dst, nil
+}
+
+// localName returns the cleaned, slash-separated name of an entry,
+// or ErrUnsafe if the name is empty, absolute or outside of the destination directory.
+func localName(name string) (string, error) {
+	s := strings.ReplaceAll(name, `\`, "/")
+	if s == "" || strings.HasPrefix(s, "/") || (len(s) > 1 && s[1] == ':') {
+		return "", fmt.Errorf("%w: %q", ErrUnsafe, name)
+	}
+	clean := path.Clean(s)
+	if clean == "." || !filepath.IsLocal(filepath.FromSlash(clean)) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafe, name)
+	}
+	return clean, nil
+}
+
+// dir extracts a directory. A "./" entry of the destination directory itself is ignored.
+func (x *extractor) dir(name string, modified time.Time) error {
+	if path.Clean(strings.ReplaceAll(name, `\`, "/")) == "." {
+		return nil
+	}
+	clean, dst, err := x.entry(name, fs.ModeDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, DirWriteReadRead); err != nil {
+		return fmt.Errorf("%s %w", name, err)
+	}
+	x.entries = append(x.entries, Extracted{Name: clean, Path: dst, Type: fs.ModeDir, Modified: modified})
+	return nil
+}
+
+// file extracts a file using the content of the reader.
+func (x *extractor) file(name string, r io.Reader, modified time.Time) error {
+	clean, dst, err := x.entry(name, 0)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, WriteWriteRead)
+	if err != nil {
+		return fmt.Errorf("%s %w", name, err)
+	}
+	// copy an extra byte to find the files that are larger than the limit
+	written, err := io.CopyN(f, r, x.size+1)
+	if cerr := f.Close(); err == nil || errors.Is(err, io.EOF) {
+		err = cerr
+	}
+	if written > x.size {
+		os.Remove(dst)
+		return fmt.Errorf("%w: %s is more than %d bytes", ErrLimit, name, x.size)
+	}
+	x.size -= written
+	if err != nil && !errors.Is(err, io.EOF) {
+		os.Remove(dst)
+		return fmt.Errorf("%s %w", name, err)
+	}
+	if !modified.IsZero() {
+		_ = os.Chtimes(dst, modified, modified)
+	}
+	x.entries = append(x.entries, Extracted{Name: clean, Path: dst, Size: written, Modified: modified})
+	return nil
+}
+
+// link extracts a symbolic link. The target must be a relative path without any ".." elements,
+// so the link and any links that it points to can only resolve to a path within the destination directory.
+func (x *extractor) link(name, target string, modified time.Time) error {
+	t := strings.ReplaceAll(target, `\`, "/")
+	if t == "" || strings.HasPrefix(t, "/") || (len(t) > 1 && t[1] == ':') {
+		return fmt.Errorf("%w: %s links to %q", ErrUnsafe, name, target)
+	}
+	for _, elem := range strings.Split(t, "/") {
+		if elem == ".." {
+			return fmt.Errorf("%w: %s links to %q", ErrUnsafe, name, target)
+		}
+	}
+	clean, dst, err := x.entry(name, fs.ModeSymlink)
+	if err != nil {
+		return err
+	}
+	if err := os.Symlink(filepath.FromSlash(t), dst); err != nil {
+		return fmt.Errorf("%s %w", name, err)
+	}
+	x.entries = append(x.entries, Extracted{Name: clean, Path: dst, Type: fs.ModeSymlink, Link: t, Modified: modified})
+	return nil
+}
+
+// hardLink extracts a tar hard link as a copy of a file that was already extracted.
+func (x *extractor) hardLink(name, target string, modified time.Time) error {
+	clean, err := localName(target)
+	if err != nil {
+		return fmt.Errorf("%w: %s links to %q", ErrUnsafe, name, target)
+	}
+	if typ, ok := x.names[strings.ToLower(clean)]; !ok || typ != 0 {
+		return fmt.Errorf("%w: %s links to %q which is not an extracted file", ErrUnsafe, name, target)
+	}
+	f, err := os.Open(filepath.Join(x.root, filepath.FromSlash(clean)))
+	if err != nil {
+		return fmt.Errorf("%s %w", name, err)
+	}
+	defer f.Close()
+	return x.file(name, f, modified)
+}
@@ -0,0 +1,261 @@
+package helper_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Defacto2/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// entry is a file, directory or link of a test archive.
+type entry struct {
+	name string
+	body string // body is the content of a file or the target of a link
+	typ  fs.FileMode
+}
+
+// zipArchive writes a zip archive of the entries and returns its path.
+func zipArchive(t *testing.T, entries ...entry) string {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, e := range entries {
+		fh := &zip.FileHeader{Name: e.name, Method: zip.Deflate, NonUTF8: true}
+		fh.SetMode(e.typ | 0o644)
+		w, err := zw.CreateHeader(fh)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(e.body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	name := filepath.Join(t.TempDir(), "TEST.ZIP")
+	require.NoError(t, os.WriteFile(name, buf.Bytes(), 0o644))
+	return name
+}
+
+// tarArchive writes a tar archive of the entries, that is optionally gzip compressed, and returns its path.
+func tarArchive(t *testing.T, compress bool, entries ...entry) string {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Typeflag: tar.TypeReg, Size: int64(len(e.body))}
+		switch e.typ {
+		case fs.ModeDir:
+			hdr.Typeflag, hdr.Size = tar.TypeDir, 0
+		case fs.ModeSymlink:
+			hdr.Typeflag, hdr.Size, hdr.Linkname = tar.TypeSymlink, 0, e.body
+		case fs.ModeIrregular:
+			hdr.Typeflag, hdr.Size, hdr.Linkname = tar.TypeLink, 0, e.body
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		if hdr.Size > 0 {
+			_, err := tw.Write([]byte(e.body))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	p, name := buf.Bytes(), filepath.Join(t.TempDir(), "test.tar")
+	if compress {
+		p, name = gzipped(t, "", p), name+".gz"
+	}
+	require.NoError(t, os.WriteFile(name, p, 0o644))
+	return name
+}
+
+// gzipped returns the gzip compressed data using the name in the header.
+func gzipped(t *testing.T, name string, p []byte) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+	zw.Name = name
+	zw.ModTime = time.Date(1994, 4, 17, 0, 0, 0, 0, time.UTC)
+	_, err := zw.Write(p)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// names returns the names of the extracted entries.
+func names(entries []helper.Extracted) []string {
+	s := make([]string, len(entries))
+	for i, e := range entries {
+		s[i] = e.Name
+	}
+	return s
+}
+
+func TestExtractDirZip(t *testing.T) {
+	t.Parallel()
+	src := zipArchive(t,
+		entry{name: "README.TXT", body: "Hello world\r\n"},
+		entry{name: "DOCS/", typ: fs.ModeDir},
+		entry{name: "DOCS\\CAF\x90.TXT", body: "Café"},
+	)
+	dst := t.TempDir()
+	entries, err := helper.ExtractDir(src, dst, helper.ExtractOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.TXT", "DOCS", "DOCS/CAFÉ.TXT"}, names(entries))
+	assert.Equal(t, int64(13), entries[0].Size)
+	assert.Equal(t, fs.ModeDir, entries[1].Type)
+	assert.Equal(t, filepath.Join(dst, "DOCS", "CAFÉ.TXT"), entries[2].Path)
+	b, err := os.ReadFile(entries[2].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "Café", string(b))
+
+	// extracting again replaces the files
+	_, err = helper.ExtractDir(src, dst, helper.ExtractOptions{})
+	require.NoError(t, err)
+}
+
+func TestExtractDirLegacy(t *testing.T) {
+	t.Parallel()
+	src := filepath.Join("pkzip", "testdata", "IMPLODED.ZIP")
+	entries, err := helper.ExtractDir(src, t.TempDir(), helper.ExtractOptions{})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	for _, e := range entries {
+		assert.Equal(t, int64(5038), e.Size, e.Name)
+		ok, err := helper.FileMatch(e.Path, filepath.Join("testdata", "PKZ80A1.TXT"))
+		require.NoError(t, err)
+		assert.True(t, ok, e.Name)
+	}
+}
+
+func TestExtractDirTar(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require privileges on windows")
+	}
+	for _, compress := range []bool{false, true} {
+		src := tarArchive(t, compress,
+			entry{name: "./", typ: fs.ModeDir},
+			entry{name: "./docs/", typ: fs.ModeDir},
+			entry{name: "./docs/file_id.diz", body: "A DIZ"},
+			entry{name: "./file_id.diz", body: "docs/file_id.diz", typ: fs.ModeSymlink},
+			entry{name: "./copy.diz", body: "docs/file_id.diz", typ: fs.ModeIrregular},
+		)
+		dst := t.TempDir()
+		entries, err := helper.ExtractDir(src, dst, helper.ExtractOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"docs", "docs/file_id.diz", "file_id.diz", "copy.diz"}, names(entries))
+		assert.Equal(t, fs.ModeSymlink, entries[2].Type)
+		assert.Equal(t, "docs/file_id.diz", entries[2].Link)
+		for _, name := range []string{"file_id.diz", "copy.diz"} {
+			b, err := os.ReadFile(filepath.Join(dst, name))
+			require.NoError(t, err)
+			assert.Equal(t, "A DIZ", string(b))
+		}
+	}
+}
+
+func TestExtractDirGzip(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "readme.gz")
+	require.NoError(t, os.WriteFile(src, gzipped(t, "../README.TXT", []byte("Hello world")), 0o644))
+	entries, err := helper.ExtractDir(src, dir, helper.ExtractOptions{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "README.TXT", entries[0].Name)
+	assert.Equal(t, int64(11), entries[0].Size)
+	assert.Equal(t, 1994, entries[0].Modified.Year())
+
+	require.NoError(t, os.WriteFile(src, gzipped(t, "", []byte("Hello world")), 0o644))
+	entries, err = helper.ExtractDir(src, dir, helper.ExtractOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"readme"}, names(entries))
+}
+
+func TestExtractDirUnsafe(t *testing.T) {
+	t.Parallel()
+	tests := []string{
+		zipArchive(t, entry{name: "../EVIL.TXT", body: "evil"}),
+		zipArchive(t, entry{name: `DOCS\..\..\EVIL.TXT`, body: "evil"}),
+		zipArchive(t, entry{name: "/EVIL.TXT", body: "evil"}),
+		zipArchive(t, entry{name: `C:\EVIL.TXT`, body: "evil"}),
+		zipArchive(t, entry{name: "LINK", body: "/etc", typ: fs.ModeSymlink}),
+		tarArchive(t, false, entry{name: "link", body: "../../etc", typ: fs.ModeSymlink}),
+		tarArchive(t, false, entry{name: "link", body: "docs/../..", typ: fs.ModeSymlink}),
+		tarArchive(t, false,
+			entry{name: "link", body: "docs", typ: fs.ModeSymlink},
+			entry{name: "LINK/evil.txt", body: "evil"}),
+		tarArchive(t, false, entry{name: "copy", body: "/etc/passwd", typ: fs.ModeIrregular}),
+	}
+	for i, src := range tests {
+		dst := t.TempDir()
+		_, err := helper.ExtractDir(src, dst, helper.ExtractOptions{})
+		require.ErrorIs(t, err, helper.ErrUnsafe, i)
+		assert.NoFileExists(t, filepath.Join(filepath.Dir(dst), "EVIL.TXT"), i)
+	}
+}
+
+func TestExtractDirDuplicate(t *testing.T) {
+	t.Parallel()
+	src := zipArchive(t,
+		entry{name: "DOCS/", typ: fs.ModeDir},
+		entry{name: "docs/", typ: fs.ModeDir},
+		entry{name: "DOCS/README.TXT", body: "first"},
+		entry{name: "docs/readme.txt", body: "second"},
+	)
+	entries, err := helper.ExtractDir(src, t.TempDir(), helper.ExtractOptions{})
+	require.ErrorIs(t, err, helper.ErrDuplicate)
+	assert.Equal(t, []string{"DOCS", "docs", "DOCS/README.TXT"}, names(entries))
+}
+
+func TestExtractDirLimits(t *testing.T) {
+	t.Parallel()
+	src := zipArchive(t,
+		entry{name: "A.TXT", body: "12345"},
+		entry{name: "B.TXT", body: "67890"},
+	)
+	entries, err := helper.ExtractDir(src, t.TempDir(), helper.ExtractOptions{Files: 1})
+	require.ErrorIs(t, err, helper.ErrLimit)
+	assert.Len(t, entries, 1)
+
+	dst := t.TempDir()
+	entries, err = helper.ExtractDir(src, dst, helper.ExtractOptions{Size: 8})
+	require.ErrorIs(t, err, helper.ErrLimit)
+	assert.Len(t, entries, 1)
+	assert.NoFileExists(t, filepath.Join(dst, "B.TXT"))
+
+	// a gzip file has no size in the header, so it is limited while it is written
+	src = filepath.Join(t.TempDir(), "bomb.gz")
+	require.NoError(t, os.WriteFile(src, gzipped(t, "BOMB", make([]byte, 1<<20)), 0o644))
+	entries, err = helper.ExtractDir(src, dst, helper.ExtractOptions{Size: 1 << 10})
+	require.ErrorIs(t, err, helper.ErrLimit)
+	assert.Empty(t, entries)
+	assert.NoFileExists(t, filepath.Join(dst, "BOMB"))
+}
+
+func TestExtractDirErrors(t *testing.T) {
+	t.Parallel()
+	_, err := helper.ExtractDir(filepath.Join("testdata", "TEST.DOC"), t.TempDir(), helper.ExtractOptions{})
+	require.ErrorIs(t, err, helper.ErrArchive)
+	_, err = helper.ExtractDir("nosuchfile", t.TempDir(), helper.ExtractOptions{})
+	require.Error(t, err)
+	_, err = helper.ExtractDir(filepath.Join("testdata", "TEST.DOC"), filepath.Join("testdata", "TEST.DOC"),
+		helper.ExtractOptions{})
+	require.ErrorIs(t, err, helper.ErrDirPath)
+}
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+	src := filepath.Join("pkzip", "testdata", "SHRUNK.ZIP")
+	dst, entries, err := helper.Extract(src, helper.ExtractOptions{})
+	require.NoError(t, err)
+	defer os.RemoveAll(dst)
+	assert.Equal(t, "artifact-content-shrunk.zip", filepath.Base(dst))
+	assert.Equal(t, []string{"PKZ80A1.TXT"}, names(entries))
+	assert.FileExists(t, filepath.Join(dst, "PKZ80A1.TXT"))
+}
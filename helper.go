@@ -2,7 +2,6 @@
 package helper
 
 import (
-	"bytes"
 	"context"
 	"crypto/rand"
 	"errors"
@@ -14,12 +13,9 @@ import (
 	"os"
 	"reflect"
 	"time"
-	"unicode/utf8"
 
 	"go.uber.org/zap"
 	"golang.org/x/text/encoding"
-	"golang.org/x/text/encoding/charmap"
-	"golang.org/x/text/encoding/unicode"
 )
 
 const (
@@ -42,6 +38,8 @@ var (
 	ErrOSFile     = errors.New("os file is nil")
 	ErrNoDir      = errors.New("not a directory")
 	ErrRead       = errors.New("could not read files")
+	ErrReader     = errors.New("reader is nil")
+	ErrWriter     = errors.New("writer is nil")
 )
 
 type contextKey string
@@ -109,138 +107,27 @@ func Day(i int) bool {
 	return false
 }
 
-// Determine returns the encoding of the plain text byte slice.
-// If the byte slice contains Unicode multi-byte characters then nil is returned.
-// Otherwise a charmap.ISO8859_1 or charmap.CodePage437 encoding is returned.
+// Determine returns the encoding of the plain text of the reader,
+// or nil if the reader is nil or cannot be read.
+// A text with valid Unicode multi-byte characters returns the unicode.UTF8 encoding.
+// Otherwise a charmap.ISO8859_1, charmap.Windows1252 or charmap.CodePage437 encoding is returned,
+// or for national MS-DOS texts a charmap.CodePage850, CodePage852, CodePage865 or CodePage866 encoding.
+// Texts with a byte order mark, or UTF-16 and UTF-32 texts without one, return the matching
+// unicode.UTF8BOM, unicode.UTF16 or utf32.UTF32 encoding.
+// Commodore and Atari texts return the codepage.PETSCII, codepage.PETSCIIShifted
+// or codepage.ATASCII encoding, and Amiga texts and Amiga ANSI art return the codepage.Topaz
+// or codepage.MicroKnight encoding, while PC ANSI art is CP-437 or ISO-8859-1.
+//
+// Use Detect to also get the ranked candidate encodings and the evidence for the result.
 func Determine(reader io.Reader) encoding.Encoding {
 	if reader == nil {
 		return nil
 	}
-	const (
-		controlStart   = 0x00  // ASCII control character start
-		controlEnd     = 0x1f  // ASCII control character end
-		undefinedStart = 0x7f  // Latin-1 undefined characters start
-		undefinedEnd   = 0x9f  // Latin-1 undefined characters end
-		escape         = 0x1b  // ASCII escape control character
-		unknownRune    = 65533 // Unicode replacement character (�)
-	)
-	// The following characters are considered whitespace characters in C
-	// with the isspace function:
-	// https://en.cppreference.com/w/c/string/byte/isspace
-	const (
-		formFeed       = '\f'
-		newline        = '\n'
-		carriageReturn = '\r'
-		tab            = '\t'
-		verticalTab    = '\v'
-	)
-
-	// KCF key qualifiers on the Commodore Amiga, see: https://wiki.amigaos.net/wiki/Keymap_Library
-
-	const (
-		kcfAltEsc = 0x9b // the Amiga had Keymap Qualifier Bits, which could be a typo to generate an Alt-Esc sequence?
-		bell      = 0x07 // ASCII bell character that is sometimes found in Amiga ANSI files
-		house     = 0x7f // CP-437 house character that displays a unique glyph in the Amiga Topaz font
-	)
-
-	p, err := io.ReadAll(reader)
+	d, err := Detect(reader)
 	if err != nil {
 		return nil
 	}
-	for _, char := range p {
-		switch {
-		case char == escape:
-			// escape control character commonly used in ANSI escaped sequences
-			continue
-		case // oddball control characters that are sometimes found in Amiga ANSI files
-			char == kcfAltEsc,
-			char == bell:
-			continue
-		case // common whitespace control characters
-			char == formFeed,
-			char == newline,
-			char == carriageReturn,
-			char == tab,
-			char == verticalTab:
-			continue
-		// case r == unknownRune:
-		// 	// when an unknown extended-ASCII character (128-255) is encountered
-		// 	continue
-		case char >= undefinedStart && char <= undefinedEnd:
-			// unused ASCII, which we can probably assumed to be CP-437
-			return charmap.CodePage437
-		case char >= controlStart && char <= controlEnd:
-			// ASCII control characters, which we can probably assumed to be CP-437 glyphs
-			return charmap.CodePage437
-		}
-	}
-	// Check for common CP-437 sequences and characters.
-	// This should be done before checking for multi-byte characters, which could be
-	// misinterpreted as UTF-8 runes.
-	if s := sequences(p); s != nil {
-		return s
-	}
-	// Check for Unicode multi-byte characters
-	// If an unknown rune is encountered then assume the encoding is
-	// using a legacy 8-bit code page encoding, such as CP-437.
-	multibyte := false
-	for _, r := range bytes.Runes(p) {
-		if utf8.RuneLen(r) > 1 {
-			if r == unknownRune {
-				break
-			}
-			multibyte = true
-		}
-	}
-	if multibyte {
-		return unicode.UTF8
-	}
-	return charmap.ISO8859_1
-}
-
-// sequences returns the encoding based on the presence of common CP-437 or ISO-8859-1 character sequences.
-// Full block, medium shade, horizontal bars and half blocks are sequences of characters that are often
-// unique to the CP-437 encoding.
-func sequences(p []byte) encoding.Encoding {
-	const (
-		shadeLight     = 0xb0 // ░
-		shadeMedium    = 0xb1 // ▒
-		shadeDark      = 0xb2 // ▓
-		singleHorizBar = 0xc4 // ─
-		doubleHorizBar = 0xcd // ═
-		fullBlock      = 0xdb // █
-		lowerHalfBlock = 0xdc // ▄
-		upperHalfBlock = 0xdf // ▀
-		interpunct     = 0xfa // ·
-	)
-	chars := []byte{
-		lowerHalfBlock,
-		upperHalfBlock,
-		doubleHorizBar,
-		singleHorizBar,
-		fullBlock,
-		interpunct,
-		shadeLight,
-		shadeMedium,
-		shadeDark,
-	}
-	for _, char := range chars {
-		const count = 4
-		subslice := bytes.Repeat([]byte{char}, count)
-		if bytes.Contains(p, subslice) {
-			return charmap.CodePage437
-		}
-	}
-	guillemets := []byte{0xae, 0xaf} // «»
-	if bytes.Contains(p, guillemets) {
-		return charmap.CodePage437
-	}
-	const bulletpoint = 0xf9 // ••
-	bulletpoints := []byte{bulletpoint, bulletpoint}
-	if bytes.Contains(p, bulletpoints) {
-		return charmap.CodePage437
-	}
-	return nil
+	return d.Encoding()
 }
 
 // Latency returns the stored, current local time.
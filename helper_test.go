@@ -11,6 +11,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/sauce"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/text/encoding/charmap"
@@ -297,3 +298,11 @@ func TestDetermineFile(t *testing.T) {
 	e := helper.Determine(r)
 	assert.Equal(t, charmap.CodePage437, e)
 }
+
+func TestDetermineSAUCE(t *testing.T) {
+	t.Parallel()
+	p, err := sauce.Append([]byte("Hello world!\r\n"), sauce.Record{Title: "Hello", Font: "IBM VGA"})
+	require.NoError(t, err)
+	e := helper.Determine(bytes.NewReader(p))
+	assert.Equal(t, charmap.ISO8859_1, e, "wanted ISO-8859-1 but got %s", e)
+}
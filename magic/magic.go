@@ -0,0 +1,479 @@
+// Package magic identifies the file formats of the BBS era and the retro computing scene
+// using the signatures, or magic numbers, of the file content rather than the filename extension.
+//
+// The formats are the archives, executables, images, tracker music modules and documents
+// that are common in the Defacto2 collection, with a fallback for the ANSI and plain texts.
+package magic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/Defacto2/helper"
+)
+
+// Sample is the number of bytes at the start of the content that are examined.
+const Sample = 4096
+
+// Type is a file format.
+type Type int
+
+const (
+	Unknown  Type = iota // Unknown is a format without a known signature.
+	ZIP                  // ZIP is a PKZIP or compatible archive.
+	ARJ                  // ARJ is an archive of the ARJ archiver by Robert Jung.
+	LHA                  // LHA is an LHarc or LHA archive, also known as LZH.
+	ARC                  // ARC is an archive of the SEA ARC or PKPAK archivers.
+	ZOO                  // ZOO is an archive of the Zoo archiver.
+	RAR                  // RAR is an archive of the RAR archiver.
+	SevenZip             // SevenZip is a 7-Zip archive.
+	CAB                  // CAB is a Microsoft Cabinet archive.
+	MZ                   // MZ is an MS-DOS executable.
+	NE                   // NE is a 16-bit Windows or OS/2 new executable.
+	PE                   // PE is a 32-bit or 64-bit Windows portable executable.
+	BMP                  // BMP is a Windows or OS/2 bitmap image.
+	PCX                  // PCX is a ZSoft Paintbrush image.
+	GIF                  // GIF is a CompuServe Graphics Interchange Format image.
+	ILBM                 // ILBM is an IFF interleaved bitmap image of Deluxe Paint and the Amiga.
+	PNG                  // PNG is a Portable Network Graphics image.
+	JPEG                 // JPEG is a JPEG image.
+	MOD                  // MOD is a ProTracker or compatible music module.
+	S3M                  // S3M is a Scream Tracker 3 music module.
+	XM                   // XM is a FastTracker 2 extended music module.
+	IT                   // IT is an Impulse Tracker music module.
+	XBin                 // XBin is an eXtended Binary text art image.
+	RIPscrip             // RIPscrip is a Remote Imaging Protocol script of vector graphics for BBS terminals.
+	Word                 // Word is a Microsoft Word document.
+	RTF                  // RTF is a Rich Text Format document.
+	PDF                  // PDF is a Portable Document Format document.
+	ANSI                 // ANSI is a text that uses the ANSI escape sequences.
+	Text                 // Text is a plain text.
+)
+
+// types are the names and MIME types of the formats.
+var types = [...]struct{ name, mime string }{
+	Unknown:  {"Unknown", "application/octet-stream"},
+	ZIP:      {"ZIP archive", "application/zip"},
+	ARJ:      {"ARJ archive", "application/x-arj"},
+	LHA:      {"LHA archive", "application/x-lzh-compressed"},
+	ARC:      {"ARC archive", "application/x-arc"},
+	ZOO:      {"Zoo archive", "application/x-zoo"},
+	RAR:      {"RAR archive", "application/vnd.rar"},
+	SevenZip: {"7-Zip archive", "application/x-7z-compressed"},
+	CAB:      {"Cabinet archive", "application/vnd.ms-cab-compressed"},
+	MZ:       {"MS-DOS executable", "application/x-msdos-program"},
+	NE:       {"Windows 16-bit executable", "application/x-ms-ne-executable"},
+	PE:       {"Windows executable", "application/vnd.microsoft.portable-executable"},
+	BMP:      {"BMP image", "image/bmp"},
+	PCX:      {"PCX image", "image/x-pcx"},
+	GIF:      {"GIF image", "image/gif"},
+	ILBM:     {"IFF ILBM image", "image/x-ilbm"},
+	PNG:      {"PNG image", "image/png"},
+	JPEG:     {"JPEG image", "image/jpeg"},
+	MOD:      {"ProTracker module", "audio/x-mod"},
+	S3M:      {"Scream Tracker 3 module", "audio/x-s3m"},
+	XM:       {"FastTracker 2 module", "audio/x-xm"},
+	IT:       {"Impulse Tracker module", "audio/x-it"},
+	XBin:     {"XBin text art", "image/x-xbin"},
+	RIPscrip: {"RIPscrip graphics", "text/x-ripscrip"},
+	Word:     {"Word document", "application/msword"},
+	RTF:      {"Rich Text Format document", "application/rtf"},
+	PDF:      {"PDF document", "application/pdf"},
+	ANSI:     {"ANSI text", "text/x-ansi"},
+	Text:     {"Plain text", "text/plain"},
+}
+
+// String returns the name of the format.
+func (t Type) String() string {
+	if t >= 0 && int(t) < len(types) {
+		return types[t].name
+	}
+	return "Type(" + strconv.Itoa(int(t)) + ")"
+}
+
+// MIME returns the media type of the format, which is application/octet-stream when unknown.
+func (t Type) MIME() string {
+	if t >= 0 && int(t) < len(types) {
+		return types[t].mime
+	}
+	return types[Unknown].mime
+}
+
+// Match is the identified format of a file.
+type Match struct {
+	Type       Type    // Type is the file format.
+	MIME       string  // MIME is the media type of the format.
+	Confidence float64 // Confidence is the likelihood of the format, from 0 for an Unknown to 1.
+}
+
+// IdentifyFile returns the format of the named file, see Identify.
+func IdentifyFile(name string) (Match, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return Match{}, fmt.Errorf("identify file %w", err)
+	}
+	defer f.Close()
+	return Identify(f)
+}
+
+// Identify returns the format of the content of the reader using its signature.
+// The confidence depends on the length and the position of the signature,
+// so a PNG image with an 8 byte signature is certain, while a PCX image is only a likely match.
+// A content that matches no signature returns an Unknown type and a zero confidence.
+func Identify(r io.ReaderAt) (Match, error) {
+	if r == nil {
+		return Match{}, helper.ErrReader
+	}
+	p := make([]byte, Sample)
+	n, err := r.ReadAt(p, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Match{}, fmt.Errorf("identify %w", err)
+	}
+	h := header{p: p[:n], r: r}
+	for _, s := range signatures {
+		if c := s.match(h); c > 0 {
+			return Match{Type: s.typ, MIME: s.typ.MIME(), Confidence: c}, nil
+		}
+	}
+	return Match{Type: Unknown, MIME: Unknown.MIME()}, nil
+}
+
+// header is the start of the content, and the reader for the signatures that are further into the content.
+type header struct {
+	p []byte
+	r io.ReaderAt
+}
+
+// at returns true if the header has the signature at the offset.
+func (h header) at(offset int, sig string) bool {
+	return len(h.p) >= offset+len(sig) && string(h.p[offset:offset+len(sig)]) == sig
+}
+
+// uint16 returns the little-endian value at the offset, or false if the header is too short.
+func (h header) uint16(offset int) (uint16, bool) {
+	if len(h.p) < offset+2 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint16(h.p[offset:]), true
+}
+
+// uint32 returns the little-endian value at the offset, or false if the header is too short.
+func (h header) uint32(offset int) (uint32, bool) {
+	if len(h.p) < offset+4 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(h.p[offset:]), true
+}
+
+// readAt returns the n bytes of the content at the offset, or nil if the content is too short.
+func (h header) readAt(offset int64, n int) []byte {
+	if offset < 0 {
+		return nil
+	}
+	if offset+int64(n) <= int64(len(h.p)) {
+		return h.p[offset : offset+int64(n)]
+	}
+	p := make([]byte, n)
+	if m, _ := h.r.ReadAt(p, offset); m < n {
+		return nil
+	}
+	return p
+}
+
+// signature is the match of a format, which returns the confidence or zero when the header does not match.
+type signature struct {
+	typ   Type
+	match func(h header) float64
+}
+
+// signatures are in the order that they are matched, with the weakest signatures last.
+var signatures = []signature{
+	{PNG, exact(1, 0, "\x89PNG\r\n\x1a\n")},
+	{GIF, exact(1, 0, "GIF87a", "GIF89a")},
+	{PDF, exact(1, 0, "%PDF-")},
+	{RTF, exact(1, 0, `{\rtf`)},
+	{SevenZip, exact(1, 0, "7z\xbc\xaf\x27\x1c")},
+	{RAR, exact(1, 0, "Rar!\x1a\x07\x00", "Rar!\x1a\x07\x01\x00")},
+	{RAR, exact(0.8, 0, "RE~^")},
+	{CAB, exact(1, 0, "MSCF\x00\x00\x00\x00")},
+	{XM, exact(1, 0, "Extended Module: ")},
+	{IT, exact(1, 0, "IMPM")},
+	{XBin, exact(1, 0, "XBIN\x1a")},
+	{ZIP, exact(1, 0, "PK\x03\x04", "PK\x07\x08")},
+	{ZIP, exact(0.9, 0, "PK\x05\x06")},
+	{ZOO, zoo},
+	{ILBM, iff},
+	{Word, word},
+	{LHA, lha},
+	{S3M, s3m},
+	{MOD, mod},
+	{PE, executable(PE)},
+	{NE, executable(NE)},
+	{MZ, executable(MZ)},
+	{BMP, bmp},
+	{JPEG, exact(0.95, 0, "\xff\xd8\xff")},
+	{ARJ, arj},
+	{PCX, pcx},
+	{ARC, arc},
+	{RIPscrip, rip},
+	{ANSI, text(ANSI)},
+	{Text, text(Text)},
+}
+
+// exact returns the match of any of the signatures at the offset.
+func exact(confidence float64, offset int, sigs ...string) func(h header) float64 {
+	return func(h header) float64 {
+		for _, sig := range sigs {
+			if h.at(offset, sig) {
+				return confidence
+			}
+		}
+		return 0
+	}
+}
+
+// zoo matches the "ZOO 2.10 Archive." text and the magic number of a Zoo archive.
+func zoo(h header) float64 {
+	const magic = 0xfdc4a7dc
+	if !h.at(0, "ZOO ") {
+		return 0
+	}
+	if v, ok := h.uint32(20); ok && v == magic {
+		return 1
+	}
+	return 0.7
+}
+
+// iff matches the FORM chunk of an ILBM image, or the PBM image of Deluxe Paint for MS-DOS.
+func iff(h header) float64 {
+	if h.at(0, "FORM") && (h.at(8, "ILBM") || h.at(8, "PBM ")) {
+		return 1
+	}
+	return 0
+}
+
+// word matches the Word for MS-DOS and Word for Windows 1 and 2 documents,
+// and the OLE2 compound documents of the later versions that have a WordDocument stream.
+func word(h header) float64 {
+	switch {
+	case h.at(0, "\x31\xbe\x00\x00\x00\xab"):
+		return 0.9
+	case h.at(0, "\x9b\xa5\x21\x00"), h.at(0, "\xdb\xa5\x2d\x00"):
+		return 0.9
+	case !h.at(0, "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"):
+		return 0
+	}
+	// the first sector of the directory has the names of the streams, encoded as UTF-16
+	shift, ok1 := h.uint16(0x1e)
+	sector, ok2 := h.uint32(0x30)
+	if !ok1 || !ok2 || shift < 7 || shift > 12 {
+		return 0
+	}
+	size := 1 << shift
+	dir := h.readAt((int64(sector)+1)*int64(size), size)
+	name := []byte("W\x00o\x00r\x00d\x00D\x00o\x00c\x00u\x00m\x00e\x00n\x00t\x00")
+	if bytes.Contains(dir, name) {
+		return 1
+	}
+	return 0
+}
+
+// lha matches the compression method of the first header of an LHA archive, such as "-lh5-".
+func lha(h header) float64 {
+	if len(h.p) < 7 || h.p[2] != '-' || h.p[6] != '-' || h.p[3] != 'l' {
+		return 0
+	}
+	switch string(h.p[4:6]) {
+	case "h0", "h1", "h2", "h3", "h4", "h5", "h6", "h7", "hd", "z4", "z5", "zs":
+		return 0.95
+	}
+	return 0
+}
+
+// s3m matches the SCRM signature and the module type of a Scream Tracker 3 module.
+func s3m(h header) float64 {
+	const moduleType = 0x10
+	if !h.at(44, "SCRM") {
+		return 0
+	}
+	if len(h.p) > 29 && h.p[29] == moduleType {
+		return 1
+	}
+	return 0.9
+}
+
+// mod matches the signature of the 31 instrument music modules, such as "M.K.", "8CHN" or "16CH".
+func mod(h header) float64 {
+	const offset = 1080
+	if len(h.p) < offset+4 {
+		return 0
+	}
+	sig := string(h.p[offset : offset+4])
+	switch sig {
+	case "M.K.", "M!K!", "M&K!", "FLT4", "FLT8", "CD81", "OKTA", "OCTA", "FA04", "FA06", "FA08":
+		return 0.9
+	}
+	// the number of channels, such as "6CHN" or "12CH"
+	if sig[1:] == "CHN" && sig[0] >= '1' && sig[0] <= '9' {
+		return 0.9
+	}
+	if sig[2:] == "CH" && sig[0] >= '1' && sig[0] <= '3' && sig[1] >= '0' && sig[1] <= '9' {
+		return 0.9
+	}
+	return 0
+}
+
+// executable returns the match of an MS-DOS executable with the MZ signature,
+// or an NE or PE executable that has the signature of the new header at the offset of the MS-DOS header.
+func executable(t Type) func(h header) float64 {
+	return func(h header) float64 {
+		if !h.at(0, "MZ") && !h.at(0, "ZM") {
+			return 0
+		}
+		const (
+			relocations = 0x18 // offset of the relocation table, which is 0x40 or more for the new executables
+			newHeader   = 0x3c // offset of the offset of the new header
+		)
+		found := MZ
+		if reloc, ok := h.uint16(relocations); ok && reloc >= 0x40 {
+			if offset, ok := h.uint32(newHeader); ok && offset >= 0x40 {
+				sig := h.readAt(int64(offset), 4)
+				switch {
+				case bytes.Equal(sig, []byte("PE\x00\x00")):
+					found = PE
+				case bytes.HasPrefix(sig, []byte("NE")):
+					found = NE
+				}
+			}
+		}
+		if found != t {
+			return 0
+		}
+		if t == MZ {
+			return 0.8
+		}
+		return 1
+	}
+}
+
+// bmp matches the BM signature and the size of a known bitmap information header.
+func bmp(h header) float64 {
+	if !h.at(0, "BM") {
+		return 0
+	}
+	size, ok := h.uint32(14)
+	if !ok {
+		return 0
+	}
+	switch size {
+	case 12, 16, 40, 52, 56, 64, 108, 124:
+		return 0.95
+	}
+	return 0
+}
+
+// arj matches the header identifier and the basic header size of an ARJ archive.
+func arj(h header) float64 {
+	const maxSize = 2600
+	if !h.at(0, "\x60\xea") {
+		return 0
+	}
+	if size, ok := h.uint16(2); ok && size > 0 && size <= maxSize {
+		return 0.9
+	}
+	return 0
+}
+
+// pcx matches the manufacturer, version, encoding and bits per pixel of a PCX image header.
+func pcx(h header) float64 {
+	const headerLen = 128
+	if len(h.p) < headerLen || h.p[0] != 0x0a {
+		return 0
+	}
+	version, encoding, bits := h.p[1], h.p[2], h.p[3]
+	switch version {
+	case 0, 2, 3, 4, 5:
+	default:
+		return 0
+	}
+	if encoding > 1 || (bits != 1 && bits != 2 && bits != 4 && bits != 8) {
+		return 0
+	}
+	xmin, _ := h.uint16(4)
+	ymin, _ := h.uint16(6)
+	xmax, _ := h.uint16(8)
+	ymax, _ := h.uint16(10)
+	if xmax < xmin || ymax < ymin {
+		return 0
+	}
+	return 0.7
+}
+
+// arc matches the marker, compression method and filename of the first header of an ARC archive.
+func arc(h header) float64 {
+	const (
+		marker  = 0x1a
+		nameLen = 13
+	)
+	if len(h.p) < 2+nameLen || h.p[0] != marker {
+		return 0
+	}
+	// the methods 1 to 9 are used by SEA ARC, and 10 and 11 by PKPAK and PKARC
+	if method := h.p[1]; method < 1 || method > 11 {
+		return 0
+	}
+	name := h.p[2 : 2+nameLen]
+	end := bytes.IndexByte(name, 0)
+	if end < 1 {
+		return 0
+	}
+	for _, c := range name[:end] {
+		if c <= ' ' || c >= 0x7f {
+			return 0
+		}
+	}
+	return 0.6
+}
+
+// rip matches the "!|" command prefix at the start of a RIPscrip script.
+func rip(h header) float64 {
+	p := bytes.TrimLeft(h.p, "\r\n")
+	if bytes.HasPrefix(p, []byte("!|")) {
+		return 0.8
+	}
+	return 0
+}
+
+// text returns the match of an ANSI or a plain text. An ANSI text has escape sequences and no NUL bytes,
+// as the art often uses the CP-437 glyphs of the control characters, while a plain text has
+// no control characters except for the common formatting controls. Any bytes after an MS-DOS
+// end-of-file marker, such as SAUCE metadata, are ignored.
+func text(t Type) func(h header) float64 {
+	return func(h header) float64 {
+		const sub = 0x1a
+		p := h.p
+		if i := bytes.IndexByte(p, sub); i >= 0 {
+			p = p[:i]
+		}
+		if len(p) == 0 || bytes.IndexByte(p, 0) >= 0 {
+			return 0
+		}
+		if t == ANSI {
+			if bytes.Contains(p, []byte("\x1b[")) {
+				return 0.6
+			}
+			return 0
+		}
+		for _, c := range p {
+			if c < ' ' && c != '\t' && c != '\n' && c != '\r' && c != '\f' && c != 0x1b {
+				return 0
+			}
+		}
+		return 0.2
+	}
+}
@@ -0,0 +1,176 @@
+package magic_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/magic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sample returns a header of the size with the signatures written at their offsets.
+func sample(size int, sigs map[int]string) []byte {
+	p := make([]byte, size)
+	for offset, sig := range sigs {
+		copy(p[offset:], sig)
+	}
+	return p
+}
+
+// executable returns an MS-DOS executable header with the signature of the new header.
+func executable(sig string) []byte {
+	p := sample(0x100, map[int]string{0: "MZ", 0x80: sig})
+	binary.LittleEndian.PutUint16(p[0x18:], 0x40)
+	binary.LittleEndian.PutUint32(p[0x3c:], 0x80)
+	return p
+}
+
+// compound returns an OLE2 compound document with a stream name in the first directory sector.
+func compound(stream string) []byte {
+	p := sample(0x600, map[int]string{0: "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"})
+	binary.LittleEndian.PutUint16(p[0x1e:], 9)
+	binary.LittleEndian.PutUint32(p[0x30:], 0)
+	for i, r := range "Root Entry" {
+		p[0x200+i*2] = byte(r)
+	}
+	for i, r := range stream {
+		p[0x280+i*2] = byte(r)
+	}
+	return p
+}
+
+func ExampleIdentify() {
+	m, err := magic.IdentifyFile(filepath.Join("..", "testdata", "TEST.BMP"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(m.Type, m.MIME)
+	// Output: BMP image image/bmp
+}
+
+func TestIdentify(t *testing.T) {
+	t.Parallel()
+	pcx := sample(128, map[int]string{0: "\x0a\x05\x01\x08"})
+	binary.LittleEndian.PutUint16(pcx[8:], 319)
+	binary.LittleEndian.PutUint16(pcx[10:], 199)
+	tests := []struct {
+		name   string
+		p      []byte
+		expect magic.Type
+	}{
+		{"zip", []byte("PK\x03\x04\x14\x00"), magic.ZIP},
+		{"empty zip", sample(22, map[int]string{0: "PK\x05\x06"}), magic.ZIP},
+		{"arj", []byte("\x60\xea\x26\x00\x1e"), magic.ARJ},
+		{"lha", []byte("\x24\x1a-lh5-\x00\x10"), magic.LHA},
+		{"larc", []byte("\x24\x1a-lz5-\x00\x10"), magic.LHA},
+		{"arc", sample(29, map[int]string{0: "\x1a\x08README.TXT"}), magic.ARC},
+		{"zoo", sample(34, map[int]string{0: "ZOO 2.10 Archive.\x1a", 20: "\xdc\xa7\xc4\xfd"}), magic.ZOO},
+		{"rar", []byte("Rar!\x1a\x07\x00\xcf\x90"), magic.RAR},
+		{"rar5", []byte("Rar!\x1a\x07\x01\x00"), magic.RAR},
+		{"7z", []byte("7z\xbc\xaf\x27\x1c\x00\x04"), magic.SevenZip},
+		{"cab", sample(36, map[int]string{0: "MSCF"}), magic.CAB},
+		{"ms-dos", sample(64, map[int]string{0: "MZ"}), magic.MZ},
+		{"ms-dos zm", sample(64, map[int]string{0: "ZM"}), magic.MZ},
+		{"dos extender", executable("LE"), magic.MZ},
+		{"windows 3", executable("NE"), magic.NE},
+		{"windows 95", executable("PE\x00\x00"), magic.PE},
+		{"bmp", sample(54, map[int]string{0: "BM", 14: "\x28"}), magic.BMP},
+		{"os/2 bmp", sample(26, map[int]string{0: "BM", 14: "\x0c"}), magic.BMP},
+		{"pcx", pcx, magic.PCX},
+		{"gif87", []byte("GIF87a\x40\x01"), magic.GIF},
+		{"gif89", []byte("GIF89a\x40\x01"), magic.GIF},
+		{"ilbm", []byte("FORM\x00\x00\x10\x00ILBMBMHD"), magic.ILBM},
+		{"pbm", []byte("FORM\x00\x00\x10\x00PBM BMHD"), magic.ILBM},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"), magic.PNG},
+		{"jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF"), magic.JPEG},
+		{"mod", sample(1084, map[int]string{0: "SPACE DEBRIS", 1080: "M.K."}), magic.MOD},
+		{"mod 8 channels", sample(1084, map[int]string{1080: "8CHN"}), magic.MOD},
+		{"mod 16 channels", sample(1084, map[int]string{1080: "16CH"}), magic.MOD},
+		{"s3m", sample(48, map[int]string{0: "2ND_PM", 28: "\x1a\x10", 44: "SCRM"}), magic.S3M},
+		{"xm", []byte("Extended Module: Unreal ][\x1a"), magic.XM},
+		{"it", []byte("IMPMDope\x00"), magic.IT},
+		{"xbin", []byte("XBIN\x1a\x50\x00\x19\x00"), magic.XBin},
+		{"ripscrip", []byte("\r\n!|*|Kw00000000\r\n!|1K\r\n"), magic.RIPscrip},
+		{"word", compound("WordDocument"), magic.Word},
+		{"word for dos", []byte("\x31\xbe\x00\x00\x00\xab\x00\x00"), magic.Word},
+		{"word for windows 2", []byte("\xdb\xa5\x2d\x00\x00\x00"), magic.Word},
+		{"rtf", []byte(`{\rtf1\ansi\deff0`), magic.RTF},
+		{"pdf", []byte("%PDF-1.4\n"), magic.PDF},
+		{"ansi", []byte("\x1b[0;1;37;44m Defacto2 \x1b[0m\r\n\x1aSAUCE00\x00"), magic.ANSI},
+		{"text", []byte("Greetings to all \xb0\xb1\xb2\r\n"), magic.Text},
+		{"excel", compound("Workbook"), magic.Unknown},
+		{"ms-dos bytes", []byte("MZ"), magic.MZ},
+		{"bmp text", []byte("BM\x00\x01\x02"), magic.Unknown},
+		{"binary", []byte{0x00, 0x01, 0x02, 0x03}, magic.Unknown},
+		{"empty", nil, magic.Unknown},
+	}
+	for _, tt := range tests {
+		m, err := magic.Identify(bytes.NewReader(tt.p))
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.expect, m.Type, tt.name)
+		assert.Equal(t, tt.expect.MIME(), m.MIME, tt.name)
+		if tt.expect == magic.Unknown {
+			assert.Zero(t, m.Confidence, tt.name)
+			continue
+		}
+		assert.Greater(t, m.Confidence, 0.0, tt.name)
+		assert.LessOrEqual(t, m.Confidence, 1.0, tt.name)
+	}
+}
+
+func TestIdentifyConfidence(t *testing.T) {
+	t.Parallel()
+	png, err := magic.Identify(bytes.NewReader([]byte("\x89PNG\r\n\x1a\n")))
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, png.Confidence, 0)
+
+	// a zoo archive without the magic number is less certain
+	zoo, err := magic.Identify(bytes.NewReader([]byte("ZOO 2.10 Archive.\x1a")))
+	require.NoError(t, err)
+	assert.Equal(t, magic.ZOO, zoo.Type)
+	assert.Less(t, zoo.Confidence, 1.0)
+
+	// a plain text is the weakest match
+	text, err := magic.Identify(bytes.NewReader([]byte("Hello world")))
+	require.NoError(t, err)
+	assert.Less(t, text.Confidence, zoo.Confidence)
+}
+
+func TestIdentifyFile(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		expect magic.Type
+	}{
+		{filepath.Join("..", "testdata", "TEST.BMP"), magic.BMP},
+		// the file extension is .doc but the content is a plain text
+		{filepath.Join("..", "testdata", "TEST.DOC"), magic.Text},
+		{filepath.Join("..", "testdata", "PKZ80A1.TXT"), magic.Text},
+		{filepath.Join("..", "pkzip", "testdata", "IMPLODED.ZIP"), magic.ZIP},
+		{filepath.Join("..", "ansi", "testdata", "DEMO.ANS"), magic.ANSI},
+	}
+	for _, tt := range tests {
+		m, err := magic.IdentifyFile(tt.name)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.expect, m.Type, tt.name)
+	}
+	_, err := magic.IdentifyFile("nosuchfile")
+	require.Error(t, err)
+	_, err = magic.Identify(nil)
+	require.ErrorIs(t, err, helper.ErrReader)
+}
+
+func TestType(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "ZIP archive", magic.ZIP.String())
+	assert.Equal(t, "application/zip", magic.ZIP.MIME())
+	assert.Equal(t, "Type(99)", magic.Type(99).String())
+	assert.Equal(t, "application/octet-stream", magic.Type(99).MIME())
+	assert.Equal(t, "application/octet-stream", magic.Unknown.MIME())
+}
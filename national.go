@@ -0,0 +1,155 @@
+package helper
+
+// Package file national.go contains the detection of the national, MS-DOS code pages.
+
+import (
+	"unicode"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// RuleLanguage is a character that only reads as a letter of a word in a national code page.
+const RuleLanguage Rule = "national letter"
+
+// nationals are the MS-DOS code pages that are compared against CP-437,
+// in the order of preference when they are equally plausible.
+var nationals = []*charmap.Charmap{
+	charmap.CodePage437, // United States
+	charmap.CodePage865, // Nordic
+	charmap.CodePage850, // Western Europe
+	charmap.CodePage852, // Central Europe
+	charmap.CodePage866, // Cyrillic Russian
+}
+
+// national returns the most plausible MS-DOS code page for the text
+// and the plausibility scores of every national code page.
+//
+// Each extended character is decoded using the code page and scored by
+// its neighbouring characters. A letter within a word of the same script scores a point,
+// while a letter next to a letter of another script, or a symbol within a word, loses a point.
+// So the Russian text of a CP-866 file will read as Cyrillic words,
+// while in CP-437 the same text will read as line drawing garbage mixed with Latin letters.
+func (x *examination) national(p []byte) (*charmap.Charmap, map[*charmap.Charmap]int) {
+	scores := make(map[*charmap.Charmap]int, len(nationals))
+	best := charmap.CodePage437
+	if x.utf8 {
+		return best, scores
+	}
+	for _, cm := range nationals {
+		scores[cm] = plausibility(p, cm)
+		if scores[cm] > scores[best] {
+			best = cm
+		}
+	}
+	if best == charmap.CodePage437 {
+		return best, scores
+	}
+	const extended = 0x80
+	us, bt := letters(charmap.CodePage437), letters(best)
+	for i, char := range p {
+		if char < extended {
+			continue
+		}
+		if plausible(p, i, &bt) > 0 && plausible(p, i, &us) <= 0 {
+			x.add(RuleLanguage, i, p[i:i+1], best)
+		}
+	}
+	return best, scores
+}
+
+// plausibility returns the sum of the plausible scores of the extended characters in p,
+// when they are decoded with the code page.
+func plausibility(p []byte, cm *charmap.Charmap) int {
+	const extended = 0x80
+	score := 0
+	table := letters(cm)
+	for i, char := range p {
+		if char < extended {
+			continue
+		}
+		score += plausible(p, i, &table)
+	}
+	return score
+}
+
+// Writing systems of the decoded characters of a code page.
+const (
+	notLetter = iota
+	latin
+	cyrillic
+	greek
+	otherScript
+)
+
+// Letter cases of the decoded characters of a code page, which are combined with the writing system.
+const (
+	upperCase  = 1 << 4
+	lowerCase  = 1 << 5
+	scriptMask = upperCase - 1
+)
+
+// letters returns the writing system and the letter case of every character of the code page.
+func letters(cm *charmap.Charmap) [256]byte {
+	var table [256]byte
+	for i := range table {
+		r := cm.DecodeByte(byte(i))
+		table[i] = script(r)
+		switch {
+		case table[i] == notLetter:
+		case unicode.IsUpper(r):
+			table[i] |= upperCase
+		case unicode.IsLower(r):
+			table[i] |= lowerCase
+		}
+	}
+	return table
+}
+
+// plausible returns 1 if the decoded character at index i of p is a letter within a word,
+// -1 if it is out of place within a word, or 0 if it is not within a word.
+// A letter is out of place next to a letter of another script, or when a lower case letter
+// is followed by an upper case letter, such as the ¡ and ¿ of CP-437 decoded as letters of CP-852.
+// The table is the writing system and letter case of each character of the code page.
+func plausible(p []byte, i int, table *[256]byte) int {
+	c := table[p[i]]
+	prevc, nextc := byte(notLetter), byte(notLetter)
+	if i > 0 {
+		prevc = table[p[i-1]]
+	}
+	if i < len(p)-1 {
+		nextc = table[p[i+1]]
+	}
+	r, prev, next := c&scriptMask, prevc&scriptMask, nextc&scriptMask
+	pl, nl := prev != notLetter, next != notLetter
+	switch {
+	case !pl && !nl:
+		return 0
+	case r == notLetter:
+		if pl && nl {
+			return -1
+		}
+		return 0
+	case pl && prev != r,
+		nl && next != r:
+		return -1
+	case prevc&lowerCase != 0 && c&upperCase != 0,
+		c&lowerCase != 0 && nextc&upperCase != 0:
+		return -1
+	}
+	return 1
+}
+
+// script returns the writing system of the character.
+func script(r rune) byte {
+	switch {
+	case !unicode.IsLetter(r):
+		return notLetter
+	case unicode.Is(unicode.Latin, r):
+		return latin
+	case unicode.Is(unicode.Cyrillic, r):
+		return cyrillic
+	case unicode.Is(unicode.Greek, r):
+		return greek
+	}
+	return otherScript
+}
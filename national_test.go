@@ -0,0 +1,44 @@
+package helper_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestDetermineNational(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		text   string
+		expect *charmap.Charmap
+	}{
+		{"us", "Café crème, Jürgen Björk ────────", charmap.CodePage437},
+		{"nordic", "Søren og Øystein på Bjørnøya", charmap.CodePage865},
+		{"western", "São Paulo, Ação e Informação ÂÊÎ", charmap.CodePage850},
+		{"central", "Łódź, Kraków, Gdańsk, Żółć", charmap.CodePage852},
+		{"cyrillic", "Привет из Москвы, лучшая группа", charmap.CodePage866},
+		{"lowercase cyrillic", "привет из москвы, лучшая группа", charmap.CodePage866},
+		{"spanish", "¡Hola! ¿Cómo estás? Éxito mañana en ESPAÑA", charmap.CodePage437},
+		{"latin-1", "Café déjà vu à Montréal, Ação", charmap.ISO8859_1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p, err := tt.expect.NewEncoder().Bytes([]byte(tt.text))
+			require.NoError(t, err)
+			d, err := helper.Detect(bytes.NewReader(p))
+			require.NoError(t, err)
+			var e encoding.Encoding = tt.expect
+			assert.Equal(t, e, d.Encoding(), "wanted %s but got %s", tt.expect, d.Encoding())
+			if tt.expect != charmap.CodePage437 && tt.expect != charmap.ISO8859_1 {
+				assert.Positive(t, d.Tally[helper.RuleLanguage])
+			}
+		})
+	}
+}
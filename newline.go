@@ -0,0 +1,278 @@
+package helper
+
+// Package file newline.go contains the functions to detect and convert the line endings of texts.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Defacto2/helper/sauce"
+	"golang.org/x/text/transform"
+)
+
+// Newline is the line ending style of a text.
+type Newline int
+
+const (
+	NewlineNone  Newline = iota // NewlineNone is a text without line endings.
+	NewlineLF                   // NewlineLF is the line feed used by Unix, Linux and macOS.
+	NewlineCRLF                 // NewlineCRLF is the carriage return and line feed used by MS-DOS and Windows.
+	NewlineCR                   // NewlineCR is the carriage return used by classic Mac OS and some Amiga texts.
+	NewlineMixed                // NewlineMixed is a text with more than one line ending style.
+)
+
+// String returns the name of the line ending style.
+func (n Newline) String() string {
+	switch n {
+	case NewlineNone:
+		return "none"
+	case NewlineLF:
+		return "LF"
+	case NewlineCRLF:
+		return "CRLF"
+	case NewlineCR:
+		return "CR"
+	case NewlineMixed:
+		return "mixed"
+	}
+	return fmt.Sprintf("Newline(%d)", int(n))
+}
+
+// Bytes returns the control characters of the line ending style,
+// or nil if the style is not a line ending.
+func (n Newline) Bytes() []byte {
+	switch n {
+	case NewlineLF:
+		return []byte{'\n'}
+	case NewlineCRLF:
+		return []byte{'\r', '\n'}
+	case NewlineCR:
+		return []byte{'\r'}
+	case NewlineNone, NewlineMixed:
+	}
+	return nil
+}
+
+// ErrNewline is returned when the line ending style cannot be used to convert a text.
+var ErrNewline = errors.New("newline style is not a line ending")
+
+// Newlines is the count of each line ending in a text.
+type Newlines struct {
+	LF   int // LF is the number of line feeds that are not part of a CRLF.
+	CRLF int // CRLF is the number of carriage returns followed by a line feed.
+	CR   int // CR is the number of carriage returns that are not part of a CRLF.
+}
+
+// Total returns the number of line endings.
+func (n Newlines) Total() int {
+	return n.LF + n.CRLF + n.CR
+}
+
+// Style returns the line ending style of the text.
+func (n Newlines) Style() Newline {
+	styles := 0
+	style := NewlineNone
+	for _, s := range []struct {
+		count int
+		style Newline
+	}{{n.LF, NewlineLF}, {n.CRLF, NewlineCRLF}, {n.CR, NewlineCR}} {
+		if s.count > 0 {
+			styles++
+			style = s.style
+		}
+	}
+	if styles > 1 {
+		return NewlineMixed
+	}
+	return style
+}
+
+// counter counts the line endings of a text that is read in chunks.
+type counter struct {
+	Newlines
+	cr       bool // cr is true when the last counted character was a carriage return
+	trailing bool // trailing is true when the last line has no line ending
+}
+
+// count counts the line endings of p, which continues the previous chunks.
+func (c *counter) count(p []byte) {
+	for _, char := range p {
+		switch {
+		case char == '\n' && c.cr:
+			c.CRLF++
+		case char == '\n':
+			c.LF++
+		case c.cr:
+			c.CR++
+		}
+		c.cr = char == '\r'
+	}
+	if len(p) > 0 {
+		last := p[len(p)-1]
+		c.trailing = last != '\n' && last != '\r'
+	}
+}
+
+// end counts the final carriage return of the text.
+func (c *counter) end() {
+	if c.cr {
+		c.CR++
+		c.cr = false
+	}
+}
+
+// CountNewlines returns the count of each line ending in the text of the reader.
+func CountNewlines(r io.Reader) (Newlines, error) {
+	if r == nil {
+		return Newlines{}, ErrReader
+	}
+	c, err := countReader(r)
+	return c.Newlines, err
+}
+
+// CountLines returns the number of lines in the text of the reader.
+// The lines can be of any length and use any of the LF, CRLF or CR line endings,
+// and a final line without a line ending is counted.
+func CountLines(r io.Reader) (int, error) {
+	if r == nil {
+		return 0, ErrReader
+	}
+	c, err := countReader(r)
+	if err != nil {
+		return 0, err
+	}
+	return c.lines(), nil
+}
+
+// lines returns the number of lines that were counted.
+func (c *counter) lines() int {
+	n := c.Total()
+	if c.trailing {
+		n++
+	}
+	return n
+}
+
+// countReader counts the line endings of the text of the reader.
+func countReader(r io.Reader) (counter, error) {
+	const size = 32 * 1024
+	var c counter
+	buf := make([]byte, size)
+	for {
+		n, err := r.Read(buf)
+		c.count(buf[:n])
+		if errors.Is(err, io.EOF) {
+			c.end()
+			return c, nil
+		}
+		if err != nil {
+			return counter{}, fmt.Errorf("count newlines %w", err)
+		}
+	}
+}
+
+// LineEndings returns the count of each line ending in the named file.
+// Any SAUCE metadata at the end of the file is ignored.
+func LineEndings(name string) (Newlines, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return Newlines{}, fmt.Errorf("line endings os.open %w", err)
+	}
+	defer file.Close()
+	st, err := file.Stat()
+	if err != nil {
+		return Newlines{}, fmt.Errorf("line endings file.stat %w", err)
+	}
+	return CountNewlines(io.LimitReader(file, sauce.DataSize(file, st.Size())))
+}
+
+// NewNewlineReader returns a reader that converts the line endings of r to the style.
+func NewNewlineReader(r io.Reader, style Newline) (io.Reader, error) {
+	if r == nil {
+		return nil, ErrReader
+	}
+	t, err := newlineTransformer(style)
+	if err != nil {
+		return nil, err
+	}
+	return transform.NewReader(r, t), nil
+}
+
+// NewNewlineWriter returns a writer that converts the line endings written to w to the style.
+// The writer must be closed to flush any buffered text.
+func NewNewlineWriter(w io.Writer, style Newline) (io.WriteCloser, error) {
+	if w == nil {
+		return nil, ErrWriter
+	}
+	t, err := newlineTransformer(style)
+	if err != nil {
+		return nil, err
+	}
+	return transform.NewWriter(w, t), nil
+}
+
+// NormalizeNewlines returns p with the line endings converted to the style.
+func NormalizeNewlines(p []byte, style Newline) ([]byte, error) {
+	t, err := newlineTransformer(style)
+	if err != nil {
+		return nil, err
+	}
+	b, _, err := transform.Bytes(t, p)
+	if err != nil {
+		return nil, fmt.Errorf("normalize newlines %w", err)
+	}
+	return b, nil
+}
+
+// newlineTransformer returns a transformer that converts the line endings to the style.
+func newlineTransformer(style Newline) (*newlines, error) {
+	eol := style.Bytes()
+	if eol == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNewline, style)
+	}
+	return &newlines{eol: eol}, nil
+}
+
+// newlines is a transformer that converts the CRLF, CR and LF line endings to a line ending.
+type newlines struct {
+	eol []byte // eol is the line ending that replaces the line endings
+	cr  bool   // cr is true when the last transformed character was a carriage return
+}
+
+// Reset implements the transform.Transformer interface.
+func (n *newlines) Reset() {
+	n.cr = false
+}
+
+// Transform implements the transform.Transformer interface.
+func (n *newlines) Transform(dst, src []byte, _ bool) (int, int, error) {
+	nDst, nSrc := 0, 0
+	for nSrc < len(src) {
+		char := src[nSrc]
+		if n.cr && char == '\n' {
+			// the line feed of a CRLF that was already written
+			n.cr = false
+			nSrc++
+			continue
+		}
+		if char != '\r' && char != '\n' {
+			if nDst >= len(dst) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			n.cr = false
+			dst[nDst] = char
+			nDst++
+			nSrc++
+			continue
+		}
+		if nDst+len(n.eol) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		n.cr = char == '\r'
+		nDst += copy(dst[nDst:], n.eol)
+		nSrc++
+	}
+	return nDst, nSrc, nil
+}
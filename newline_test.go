@@ -0,0 +1,160 @@
+package helper_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/sauce"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountNewlines(t *testing.T) {
+	t.Parallel()
+	_, err := helper.CountNewlines(nil)
+	require.ErrorIs(t, err, helper.ErrReader)
+
+	tests := []struct {
+		text   string
+		expect helper.Newlines
+		style  helper.Newline
+	}{
+		{"", helper.Newlines{}, helper.NewlineNone},
+		{"no line ending", helper.Newlines{}, helper.NewlineNone},
+		{"one\ntwo\n", helper.Newlines{LF: 2}, helper.NewlineLF},
+		{"one\r\ntwo\r\nthree", helper.Newlines{CRLF: 2}, helper.NewlineCRLF},
+		{"one\rtwo\r", helper.Newlines{CR: 2}, helper.NewlineCR},
+		{"\r\r\n\n\r", helper.Newlines{LF: 1, CRLF: 1, CR: 2}, helper.NewlineMixed},
+		{"one\n\rtwo", helper.Newlines{LF: 1, CR: 1}, helper.NewlineMixed},
+	}
+	for _, tt := range tests {
+		// a one byte reader splits every CRLF between reads
+		n, err := helper.CountNewlines(iotest.OneByteReader(strings.NewReader(tt.text)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.expect, n, "%q", tt.text)
+		assert.Equal(t, tt.style, n.Style(), "%q", tt.text)
+		assert.Equal(t, strings.Count(tt.text, "\n")+strings.Count(tt.text, "\r")-tt.expect.CRLF, n.Total())
+	}
+
+	_, err = helper.CountNewlines(iotest.ErrReader(io.ErrUnexpectedEOF))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestCountLines(t *testing.T) {
+	t.Parallel()
+	_, err := helper.CountLines(nil)
+	require.ErrorIs(t, err, helper.ErrReader)
+
+	tests := []struct {
+		text  string
+		lines int
+	}{
+		{"", 0},
+		{"one", 1},
+		{"one\n", 1},
+		{"\n", 1},
+		{"one\ntwo", 2},
+		{"one\r\ntwo\r\n", 2},
+		{"one\rtwo\rthree", 3},
+		{"one\r\rthree\r", 3},
+		{"one\n\r\ntwo\rthree", 4},
+	}
+	for _, tt := range tests {
+		i, err := helper.CountLines(iotest.OneByteReader(strings.NewReader(tt.text)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.lines, i, "%q", tt.text)
+	}
+
+	long := strings.Repeat("x", 1024*1024)
+	i, err := helper.CountLines(strings.NewReader(long + "\r" + long))
+	require.NoError(t, err)
+	assert.Equal(t, 2, i)
+
+	_, err = helper.CountLines(iotest.ErrReader(io.ErrUnexpectedEOF))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestNewlineString(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "CRLF", helper.NewlineCRLF.String())
+	assert.Equal(t, "mixed", helper.NewlineMixed.String())
+	assert.Equal(t, "Newline(9)", helper.Newline(9).String())
+	assert.Equal(t, []byte("\r\n"), helper.NewlineCRLF.Bytes())
+	assert.Nil(t, helper.NewlineMixed.Bytes())
+}
+
+func TestLineEndings(t *testing.T) {
+	t.Parallel()
+	_, err := helper.LineEndings("nosuchfile")
+	require.Error(t, err)
+
+	n, err := helper.LineEndings(filepath.Join("testdata", "INFINITY.NFO"))
+	require.NoError(t, err)
+	assert.Equal(t, helper.NewlineCRLF, n.Style())
+
+	// the line endings of a SAUCE comment are not counted
+	p, err := sauce.Append([]byte("one\ntwo\n"), sauce.Record{Comments: []string{"three\r\nfour\r"}})
+	require.NoError(t, err)
+	name := filepath.Join(t.TempDir(), "sauce.txt")
+	require.NoError(t, os.WriteFile(name, p, helper.WriteWriteRead))
+	n, err = helper.LineEndings(name)
+	require.NoError(t, err)
+	assert.Equal(t, helper.Newlines{LF: 2}, n)
+}
+
+func TestNormalizeNewlines(t *testing.T) {
+	t.Parallel()
+	const text = "a\r\nb\rc\nd\r\n\r\n"
+	tests := []struct {
+		style  helper.Newline
+		expect string
+	}{
+		{helper.NewlineLF, "a\nb\nc\nd\n\n"},
+		{helper.NewlineCRLF, "a\r\nb\r\nc\r\nd\r\n\r\n"},
+		{helper.NewlineCR, "a\rb\rc\rd\r\r"},
+	}
+	for _, tt := range tests {
+		p, err := helper.NormalizeNewlines([]byte(text), tt.style)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expect, string(p), tt.style.String())
+	}
+	_, err := helper.NormalizeNewlines([]byte(text), helper.NewlineMixed)
+	require.ErrorIs(t, err, helper.ErrNewline)
+}
+
+func TestNewNewlineReader(t *testing.T) {
+	t.Parallel()
+	_, err := helper.NewNewlineReader(nil, helper.NewlineLF)
+	require.ErrorIs(t, err, helper.ErrReader)
+	_, err = helper.NewNewlineReader(strings.NewReader(""), helper.NewlineNone)
+	require.ErrorIs(t, err, helper.ErrNewline)
+
+	s := strings.Repeat("abc\n\rdef\r\n", 5000)
+	r, err := helper.NewNewlineReader(iotest.OneByteReader(strings.NewReader(s)), helper.NewlineCRLF)
+	require.NoError(t, err)
+	p, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("abc\r\n\r\ndef\r\n", 5000), string(p))
+}
+
+func TestNewNewlineWriter(t *testing.T) {
+	t.Parallel()
+	_, err := helper.NewNewlineWriter(nil, helper.NewlineLF)
+	require.ErrorIs(t, err, helper.ErrWriter)
+
+	var b bytes.Buffer
+	w, err := helper.NewNewlineWriter(&b, helper.NewlineLF)
+	require.NoError(t, err)
+	for _, s := range []string{"one\r", "\ntwo\r", "three\r", ""} {
+		_, err = io.WriteString(w, s)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	assert.Equal(t, "one\ntwo\nthree\n", b.String())
+}
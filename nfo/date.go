@@ -0,0 +1,147 @@
+package nfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Defacto2/helper"
+)
+
+// ReleaseDate returns the date of the text as a "2024-07-15", "2024-07" or "2024" string
+// that can be used by helper.Released, or an empty string when there is no date.
+//
+// The numeric dates use the MS-DOS convention of the United States, "04/17/1994" or "04-17-94",
+// unless the first number cannot be a month. Dates using dots, "17.04.1994", are European.
+// The dates using names, "17 April 1994", "Apr 17th, 1994" or "April 1994", are also understood.
+// Two digit years are in the 1900s from 80, otherwise they are in the 2000s.
+func ReleaseDate(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var (
+		nums  []int
+		month int
+	)
+	for _, word := range words {
+		if m := monthName(word); m > 0 {
+			if month == 0 {
+				month = m
+			}
+			continue
+		}
+		word = strings.TrimRightFunc(word, unicode.IsLetter) // the ordinal suffix of 17th
+		if i, err := strconv.Atoi(word); err == nil {
+			nums = append(nums, i)
+		}
+	}
+	if month > 0 {
+		return named(month, nums)
+	}
+	return numeric(nums, strings.Contains(s, "."))
+}
+
+// named returns the date of a month name and the numbers of the day and year.
+func named(month int, nums []int) string {
+	const minYear = 100
+	year, day := 0, 0
+	for _, i := range nums {
+		if i >= minYear && year == 0 {
+			year = i
+		}
+	}
+	for _, i := range nums {
+		if i >= minYear {
+			continue
+		}
+		switch {
+		case day == 0 && helper.Day(i) && (year > 0 || len(nums) > 1):
+			day = i
+		case year == 0:
+			year = century(i)
+		}
+	}
+	return iso(year, month, day)
+}
+
+// numeric returns the date of the numbers of a numeric date,
+// which is day first when european is true.
+func numeric(nums []int, european bool) string {
+	const (
+		minYear  = 100
+		maxMonth = 12
+	)
+	switch len(nums) {
+	case 1:
+		if nums[0] >= minYear {
+			return iso(nums[0], 0, 0)
+		}
+	case 2:
+		// 1994-04 or 04/1994
+		if nums[0] >= minYear {
+			return iso(nums[0], nums[1], 0)
+		}
+		if nums[1] >= minYear {
+			return iso(nums[1], nums[0], 0)
+		}
+	case 3:
+		if nums[0] >= minYear {
+			return iso(nums[0], nums[1], nums[2])
+		}
+		month, day := nums[0], nums[1]
+		if european || month > maxMonth {
+			month, day = day, month
+		}
+		return iso(century(nums[2]), month, day)
+	}
+	return ""
+}
+
+// century returns the four digit year of a two digit year.
+func century(year int) int {
+	const (
+		minYear = 100
+		y2k     = 80
+	)
+	switch {
+	case year >= minYear || year < 0:
+		return year
+	case year >= y2k:
+		return 1900 + year
+	default:
+		return 2000 + year
+	}
+}
+
+// iso returns the date as a "2024-07-15", "2024-07" or "2024" string,
+// or an empty string when the year is invalid. An invalid month or day is ignored.
+func iso(year, month, day int) string {
+	if !helper.Year(year) {
+		return ""
+	}
+	if month < 1 || month > 12 {
+		return strconv.Itoa(year)
+	}
+	if !helper.Day(day) {
+		return fmt.Sprintf("%d-%02d", year, month)
+	}
+	return fmt.Sprintf("%d-%02d-%02d", year, month, day)
+}
+
+// monthName returns the month of the English name or abbreviation, or zero when it is not a month.
+func monthName(s string) int {
+	const abbr = 3
+	if len(s) < abbr {
+		return 0
+	}
+	s = strings.ToLower(s)
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if strings.HasPrefix(name, s) {
+			return int(m)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+package nfo_test
+
+import (
+	"testing"
+
+	"github.com/Defacto2/helper/nfo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseDate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		s, expect string
+	}{
+		{"04/17/1994", "1994-04-17"},
+		{"04-17-94", "1994-04-17"},
+		{"17/04/94", "1994-04-17"},
+		{"17.04.1994", "1994-04-17"},
+		{"1.2.03", "2003-02-01"},
+		{"1994-04-17", "1994-04-17"},
+		{"1994-04", "1994-04"},
+		{"04/1994", "1994-04"},
+		{"1994", "1994"},
+		{"17 April 1994", "1994-04-17"},
+		{"Apr 17th, 1994", "1994-04-17"},
+		{"17-Apr-94", "1994-04-17"},
+		{"April 1994", "1994-04"},
+		{"sept. 94", "1994-09"},
+		{"13/13/1994", "1994"},
+		{"1969", ""},
+		{"Mayhem Edition", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expect, nfo.ReleaseDate(tt.s), tt.s)
+	}
+}
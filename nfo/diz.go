@@ -0,0 +1,54 @@
+package nfo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Defacto2/helper"
+)
+
+const (
+	DIZWidth = 45 // DIZWidth is the maximum number of columns of a FILE_ID.DIZ line.
+	DIZLines = 10 // DIZLines is the maximum number of lines of a FILE_ID.DIZ.
+)
+
+var (
+	ErrDIZEmpty = errors.New("file_id.diz is empty")
+	ErrDIZLines = errors.New("file_id.diz has too many lines")
+	ErrDIZWidth = errors.New("file_id.diz line is too wide")
+)
+
+// ValidateDIZ returns an error if the FILE_ID.DIZ text of the reader breaks the convention
+// of the BBS upload processors, which is a description of up to 10 lines of 45 columns.
+// The trailing whitespace of the lines and the trailing empty lines are ignored.
+func ValidateDIZ(r io.Reader) error {
+	if r == nil {
+		return helper.ErrReader
+	}
+	p, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("validate diz %w", err)
+	}
+	s, _, err := decode(p)
+	if err != nil {
+		return fmt.Errorf("validate diz %w", err)
+	}
+	lines := strings.Split(strings.TrimRightFunc(s, unicode.IsSpace), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return ErrDIZEmpty
+	}
+	if len(lines) > DIZLines {
+		return fmt.Errorf("%w: %d lines", ErrDIZLines, len(lines))
+	}
+	for i, line := range lines {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if n := utf8.RuneCountInString(line); n > DIZWidth {
+			return fmt.Errorf("%w: line %d has %d columns", ErrDIZWidth, i+1, n)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package nfo_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/nfo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDIZ(t *testing.T) {
+	t.Parallel()
+	require.ErrorIs(t, nfo.ValidateDIZ(nil), helper.ErrReader)
+
+	f, err := os.Open("testdata/FILE_ID.DIZ")
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, nfo.ValidateDIZ(f))
+
+	f, err = os.Open("../testdata/INFINITY.NFO")
+	require.NoError(t, err)
+	defer f.Close()
+	require.ErrorIs(t, nfo.ValidateDIZ(f), nfo.ErrDIZLines)
+
+	wide := strings.Repeat("x", nfo.DIZWidth) + "\n" + strings.Repeat("ü", nfo.DIZWidth+1) + "\n"
+	err = nfo.ValidateDIZ(strings.NewReader(wide))
+	require.ErrorIs(t, err, nfo.ErrDIZWidth)
+	require.ErrorContains(t, err, "line 2 has 46 columns")
+
+	// trailing whitespace and empty lines are ignored
+	s := strings.Repeat("line\r\n", nfo.DIZLines) + strings.Repeat(" ", 60) + "\r\n\r\n"
+	require.NoError(t, nfo.ValidateDIZ(strings.NewReader(s)))
+
+	require.ErrorIs(t, nfo.ValidateDIZ(strings.NewReader(" \r\n")), nfo.ErrDIZEmpty)
+}
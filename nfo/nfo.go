@@ -0,0 +1,292 @@
+// Package nfo extracts the release metadata from the FILE_ID.DIZ and NFO text files
+// that are included with the scene releases and the BBS uploads.
+//
+// The texts are decoded using the encoding detection of the helper package,
+// and the metadata are hints found using the common "Key : Value" fields of the texts,
+// so they should be reviewed before use.
+package nfo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/sauce"
+	"golang.org/x/text/encoding"
+)
+
+// Role is the credited role of a person in a release.
+type Role string
+
+const (
+	Supplier Role = "supplied" // Supplier supplied the original software.
+	Cracker  Role = "cracked"  // Cracker removed the copy protection.
+	Trainer  Role = "trained"  // Trainer added the cheats or trainer menu.
+	Packer   Role = "packed"   // Packer compressed or packaged the release.
+	Ripper   Role = "ripped"   // Ripper removed the unneeded files from the release.
+)
+
+// Credit is the name of a person and their role in a release.
+type Credit struct {
+	Role Role   // Role is the credited role.
+	Name string // Name is the person or group that is credited.
+}
+
+// Info are the metadata hints of a release.
+type Info struct {
+	Title   string   // Title is the title of the release.
+	Group   string   // Group is the group that released the release.
+	Date    string   // Date is the release date as written in the text.
+	Year    int16    // Year is the year of the release date, or zero when unknown.
+	Month   int16    // Month is the month of the release date, or zero when unknown.
+	Day     int16    // Day is the day of the release date, or zero when unknown.
+	Disks   int      // Disks is the number of disks of the release, or zero when unknown.
+	Credits []Credit // Credits are the people credited in the release, in the order of the text.
+
+	Encoding encoding.Encoding // Encoding is the detected encoding of the text.
+}
+
+// Credited returns the names of the credits of the role.
+func (i Info) Credited(role Role) []string {
+	var names []string
+	for _, c := range i.Credits {
+		if c.Role == role {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// Parse returns the metadata hints of the FILE_ID.DIZ or NFO text of the reader.
+func Parse(r io.Reader) (Info, error) {
+	if r == nil {
+		return Info{}, helper.ErrReader
+	}
+	p, err := io.ReadAll(r)
+	if err != nil {
+		return Info{}, fmt.Errorf("nfo parse %w", err)
+	}
+	s, e, err := decode(p)
+	if err != nil {
+		return Info{}, fmt.Errorf("nfo parse %w", err)
+	}
+	info := Info{Encoding: e}
+	lines := strings.Split(s, "\n")
+	for _, line := range lines {
+		info.fields(line)
+	}
+	info.presents(lines)
+	if info.Disks == 0 {
+		info.Disks = disks(s)
+	}
+	if info.Date != "" {
+		info.Year, info.Month, info.Day = helper.Released(ReleaseDate(info.Date))
+	}
+	return info, nil
+}
+
+// decode returns the text decoded to UTF-8 with LF line endings and its encoding.
+// The encoding is detected using helper.Determine and any SAUCE metadata is ignored.
+func decode(p []byte) (string, encoding.Encoding, error) {
+	p = sauce.Trim(p)
+	e := helper.Determine(bytes.NewReader(p))
+	if e == nil {
+		return "", nil, helper.ErrReader
+	}
+	b, err := e.NewDecoder().Bytes(p)
+	if err != nil {
+		return "", nil, fmt.Errorf("decode %s %w", e, err)
+	}
+	b, err = helper.NormalizeNewlines(b, helper.NewlineLF)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(b), e, nil
+}
+
+// field is the metadata of a "Key : Value" field.
+type field int
+
+const (
+	title field = iota
+	group
+	date
+	disk
+	credit
+)
+
+// keys are the lower case keys of the fields, with the role of the credit fields.
+var keys = map[string]struct {
+	field field
+	role  Role
+}{
+	"release": {field: title}, "release name": {field: title}, "rls name": {field: title}, "title": {field: title},
+	"name": {field: title}, "program": {field: title}, "game": {field: title}, "app": {field: title},
+	"group": {field: group}, "released by": {field: group}, "presented by": {field: group}, "brought by": {field: group},
+	"date": {field: date}, "release date": {field: date}, "rel date": {field: date}, "rls date": {field: date},
+	"released": {field: date}, "released on": {field: date}, "date released": {field: date},
+	"disks": {field: disk}, "disk": {field: disk}, "disk count": {field: disk}, "number of disks": {field: disk},
+	"supplier": {credit, Supplier}, "supplied by": {credit, Supplier}, "supplied": {credit, Supplier},
+	"cracker": {credit, Cracker}, "cracked by": {credit, Cracker}, "cracked": {credit, Cracker}, "crack": {credit, Cracker},
+	"trainer": {credit, Trainer}, "trained by": {credit, Trainer}, "trained": {credit, Trainer},
+	"packer": {credit, Packer}, "packed by": {credit, Packer}, "packed": {credit, Packer},
+	"ripper": {credit, Ripper}, "ripped by": {credit, Ripper}, "ripped": {credit, Ripper},
+}
+
+// keyRe matches the key of a field of up to three words, followed by any dot leader and a colon.
+var keyRe = regexp.MustCompile(`([A-Za-z][A-Za-z']*(?: [A-Za-z']+){0,2})[ .]*:`)
+
+// fields adds the metadata of the "Key : Value" fields of the line.
+// A line can have more than one field, such as "Date : 04/17/1994   Disks : 2".
+func (i *Info) fields(line string) {
+	type match struct {
+		key        string
+		start, end int
+	}
+	var matches []match
+	for _, m := range keyRe.FindAllStringSubmatchIndex(line, -1) {
+		words := strings.Fields(strings.ToLower(line[m[2]:m[3]]))
+		// the key can follow other words, such as the value of a previous field
+		for n := range words {
+			key := strings.Join(words[n:], " ")
+			if _, ok := keys[key]; ok {
+				start := m[2] + strings.Index(strings.ToLower(line[m[2]:m[3]]), words[n])
+				matches = append(matches, match{key: key, start: start, end: m[1]})
+				break
+			}
+		}
+	}
+	for n, m := range matches {
+		end := len(line)
+		if n+1 < len(matches) {
+			end = matches[n+1].start
+		}
+		value := clean(line[m.end:end])
+		if value == "" {
+			continue
+		}
+		i.add(keys[m.key].field, keys[m.key].role, value)
+	}
+}
+
+// add sets the metadata of the field, unless it is already set.
+func (i *Info) add(f field, role Role, value string) {
+	switch f {
+	case title:
+		if i.Title == "" {
+			i.Title = value
+		}
+	case group:
+		if i.Group == "" {
+			i.Group = value
+		}
+	case date:
+		if i.Date == "" {
+			i.Date = value
+		}
+	case disk:
+		if i.Disks == 0 {
+			i.Disks = count(value)
+		}
+	case credit:
+		for _, name := range names(value) {
+			i.Credits = append(i.Credits, Credit{Role: role, Name: name})
+		}
+	}
+}
+
+// presentsRe matches the "Group proudly presents" line of a release.
+var presentsRe = regexp.MustCompile(`(?i)^(.+?)\s+(?:proudly\s+)?presents\b`)
+
+// presents adds the group of a "Group presents" line, and the title that follows it
+// when they are not set by the fields.
+func (i *Info) presents(lines []string) {
+	for n, line := range lines {
+		m := presentsRe.FindStringSubmatch(clean(line))
+		if m == nil {
+			continue
+		}
+		if i.Group == "" {
+			i.Group = clean(m[1])
+		}
+		if i.Title != "" {
+			return
+		}
+		for _, next := range lines[n+1:] {
+			if s := clean(next); s != "" {
+				i.Title = s
+				return
+			}
+		}
+		return
+	}
+}
+
+// clean returns the value without the surrounding whitespace and border decorations,
+// such as the box drawing and block characters.
+func clean(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || decoration(r)
+	})
+}
+
+// decoration returns true if the rune is commonly used as a border of a text.
+func decoration(r rune) bool {
+	const (
+		boxStart   = 0x2500 // ─
+		blockEnd   = 0x259f // ▟
+		shapeStart = 0x25a0 // ■
+		shapeEnd   = 0x25ff // ◿
+	)
+	switch {
+	case r >= boxStart && r <= blockEnd, r >= shapeStart && r <= shapeEnd:
+		return true
+	}
+	return strings.ContainsRune("|*=~+#.:-_∙•·", r)
+}
+
+// namesRe matches the separators of a list of names.
+var namesRe = regexp.MustCompile(`\s*(?:,|&|\+|/|\band\b)\s*`)
+
+// names returns the names of a list of credits, such as "Alice & Bob".
+func names(s string) []string {
+	var list []string
+	for _, name := range namesRe.Split(s, -1) {
+		if name = clean(name); name != "" {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// countRe matches the first number or the total of a "1/3" disk number of a value.
+var countRe = regexp.MustCompile(`(?:\d+\s*(?:/|of)\s*)?(\d+)`)
+
+// count returns the number of disks of a field value, such as "2", "2 disks" or "1/3".
+func count(s string) int {
+	m := countRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	i, _ := strconv.Atoi(m[1])
+	return i
+}
+
+// disksRe matches the disk numbers of the FILE_ID.DIZ convention, such as "[01/03]",
+// "(1/3)" or "disk 1 of 3".
+var disksRe = regexp.MustCompile(`(?i)(?:[\[(]\s*\d{1,2}\s*/\s*(\d{1,2})\s*[\])]|dis[kc]s?\s*\d{1,2}\s+of\s+(\d{1,2}))`)
+
+// disks returns the number of disks using the disk numbers of the text, or zero when none are found.
+func disks(s string) int {
+	m := disksRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	i, _ := strconv.Atoi(m[1] + m[2])
+	return i
+}
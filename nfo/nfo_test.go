@@ -0,0 +1,92 @@
+package nfo_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/nfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func ExampleParse() {
+	const s = "Release : Cafe Racer\r\nCracked by : Alice & Bob\r\nDate : April 17th, 1994\r\n"
+	info, _ := nfo.Parse(strings.NewReader(s))
+	fmt.Println(info.Title)
+	fmt.Println(info.Credited(nfo.Cracker))
+	fmt.Println(info.Year, info.Month, info.Day)
+	// Output:
+	// Cafe Racer
+	// [Alice Bob]
+	// 1994 4 17
+}
+
+func TestParseNFO(t *testing.T) {
+	t.Parallel()
+	f, err := os.Open("../testdata/INFINITY.NFO")
+	require.NoError(t, err)
+	defer f.Close()
+	info, err := nfo.Parse(f)
+	require.NoError(t, err)
+	assert.Equal(t, charmap.CodePage437, info.Encoding)
+	assert.Equal(t, "Café Racer Deluxe", info.Title)
+	assert.Equal(t, "04/17/1994", info.Date)
+	assert.Equal(t, [3]int16{1994, 4, 17}, [3]int16{info.Year, info.Month, info.Day})
+	assert.Equal(t, 2, info.Disks)
+	assert.Equal(t, []nfo.Credit{
+		{Role: nfo.Supplier, Name: "Jürgen"},
+		{Role: nfo.Cracker, Name: "Señor Björk"},
+	}, info.Credits)
+	assert.Empty(t, info.Group, "the group is only named by the logo and footer")
+}
+
+func TestParseDIZ(t *testing.T) {
+	t.Parallel()
+	f, err := os.Open("testdata/FILE_ID.DIZ")
+	require.NoError(t, err)
+	defer f.Close()
+	info, err := nfo.Parse(f)
+	require.NoError(t, err)
+	assert.Equal(t, "Infinity", info.Group)
+	assert.Equal(t, "Café Racer Deluxe v1.1 (c) Velo Soft", info.Title)
+	assert.Equal(t, "17.04.1994", info.Date)
+	assert.Equal(t, [3]int16{1994, 4, 17}, [3]int16{info.Year, info.Month, info.Day})
+	assert.Equal(t, 2, info.Disks)
+	assert.Equal(t, []string{"Jürgen", "Tom"}, info.Credited(nfo.Supplier))
+	assert.Equal(t, []string{"Señor Björk"}, info.Credited(nfo.Cracker))
+	assert.Empty(t, info.Credited(nfo.Trainer))
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	_, err := nfo.Parse(nil)
+	require.ErrorIs(t, err, helper.ErrReader)
+
+	info, err := nfo.Parse(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, info.Title)
+	assert.Empty(t, info.Credits)
+
+	const s = "| Game.........: Space Quest   Disks : 3x1.44MB |\n" +
+		"| Trained by...: Carol + Dave  Packer: Eve |\n" +
+		"| Group: Razor 1911 |\n" +
+		"| Released: Mayhem Edition |\n"
+	info, err = nfo.Parse(strings.NewReader(s))
+	require.NoError(t, err)
+	assert.Equal(t, "Space Quest", info.Title)
+	assert.Equal(t, 3, info.Disks)
+	assert.Equal(t, []string{"Carol", "Dave"}, info.Credited(nfo.Trainer))
+	assert.Equal(t, []string{"Eve"}, info.Credited(nfo.Packer))
+	assert.Equal(t, "Razor 1911", info.Group)
+	assert.Equal(t, "Mayhem Edition", info.Date)
+	assert.Zero(t, info.Year)
+
+	info, err = nfo.Parse(strings.NewReader("Cafe Racer (c) Velo Soft\nDisk 2 of 4\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, info.Disks)
+	assert.Empty(t, info.Title)
+}
@@ -3,7 +3,7 @@ package helper
 // Package file os.go contains the helper functions for file system operations.
 
 import (
-	"bufio"
+	"bytes"
 	"crypto/sha512"
 	"embed"
 	"encoding/base64"
@@ -17,7 +17,9 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
-	"unicode/utf8"
+
+	"github.com/Defacto2/helper/sauce"
+	"golang.org/x/text/encoding/unicode"
 )
 
 const (
@@ -267,24 +269,23 @@ func IntegrityBytes(b []byte) string {
 	return "sha384-" + b64
 }
 
-// Lines returns the number of lines in the named file.
+// Lines returns the number of lines in the named file, see CountLines.
+// Any SAUCE metadata and MS-DOS end-of-file markers at the end of the file are ignored.
 func Lines(name string) (int, error) {
 	file, err := os.Open(name)
 	if err != nil {
 		return 0, fmt.Errorf("integrity os.open %w", err)
 	}
 	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	lines := 0
-	for scanner.Scan() {
-		lines++
+	st, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("integrity file.stat %w", err)
 	}
-
-	if err := scanner.Err(); err != nil {
-		return 0, fmt.Errorf("integrity scanner.scan %w", err)
+	size := sauce.DataSize(file, st.Size())
+	lines, err := CountLines(io.LimitReader(file, size))
+	if err != nil {
+		return 0, fmt.Errorf("integrity %w", err)
 	}
-
 	return lines, nil
 }
 
@@ -488,18 +489,31 @@ func TouchW(name string, data ...byte) (int, error) {
 }
 
 // UTF8 returns true if the named file is a valid UTF-8 encoded file.
-// The function reads the first 512 bytes of the file to determine the encoding.
+// The function reads the first UTF8Sample bytes of the file to determine the encoding,
+// and a multibyte rune that is split by the end of the sample is not invalid.
+// A file with a UTF-8 byte order mark is always UTF-8, while a file with a UTF-16 or UTF-32
+// byte order mark, or a file that looks like BOM-less UTF-16 or UTF-32 text, is not.
 func UTF8(name string) (bool, error) {
 	f, err := os.Open(name)
 	if err != nil {
 		return false, fmt.Errorf("utf8 open %w", err)
 	}
 	defer f.Close()
-	const sample = 512
-	buf := make([]byte, sample)
-	_, err = f.Read(buf)
-	if err != nil {
+	buf := make([]byte, UTF8Sample)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
 		return false, fmt.Errorf("utf8 read %w", err)
 	}
-	return utf8.Valid(buf), nil
+	buf = buf[:n]
+	if e, _ := BOM(buf); e != nil {
+		return e == unicode.UTF8BOM, nil
+	}
+	if Wide(buf) != nil {
+		return false, nil
+	}
+	v, err := ValidateUTF8(io.MultiReader(bytes.NewReader(buf), f), UTF8Sample)
+	if err != nil {
+		return false, fmt.Errorf("utf8 %w", err)
+	}
+	return v.Valid, nil
 }
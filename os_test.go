@@ -6,11 +6,12 @@ import (
 	"testing"
 
 	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/sauce"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-const testDataFileCount = 3
+const testDataFileCount = 5
 
 func TestCount(t *testing.T) {
 	dir, err := filepath.Abs("testdata")
@@ -101,10 +102,11 @@ func TestLines(t *testing.T) {
 
 	dir, err := filepath.Abs("testdata")
 	require.NoError(t, err)
+	// a single line that is much longer than the bufio.Scanner limit
 	name := filepath.Join(dir, "TEST.BMP")
 	i, err = helper.Lines(name)
-	require.Error(t, err)
-	assert.Equal(t, 0, i)
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
 
 	name = filepath.Join(dir, "PKZ80A1.TXT")
 	i, err = helper.Lines(name)
@@ -237,3 +239,17 @@ func TestStrongIntegrity(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expected, s)
 }
+
+func TestLinesSAUCE(t *testing.T) {
+	t.Parallel()
+	p, err := sauce.Append([]byte("one\r\ntwo\r\nthree"), sauce.Record{
+		Title:    "Lines",
+		Comments: []string{"a comment", "that is not counted"},
+	})
+	require.NoError(t, err)
+	name := filepath.Join(t.TempDir(), "sauce.txt")
+	require.NoError(t, os.WriteFile(name, p, helper.WriteWriteRead))
+	i, err := helper.Lines(name)
+	require.NoError(t, err)
+	assert.Equal(t, 3, i)
+}
@@ -0,0 +1,277 @@
+package pkzip
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"math"
+	"sync"
+)
+
+var (
+	ErrData   = errors.New("pkzip compressed data is invalid")
+	ErrMethod = errors.New("pkzip compression method is not supported")
+	ErrSize   = errors.New("pkzip uncompressed size is unknown")
+)
+
+// The general purpose bit flags of an entry that are used by the legacy methods.
+const (
+	flagEncrypted  = 1 << 0 // flagEncrypted is set when the entry is encrypted.
+	flagDictionary = 1 << 1 // flagDictionary is set when Imploded uses the 8K sliding dictionary.
+	flagTrees      = 1 << 2 // flagTrees is set when Imploded uses three Shannon-Fano trees.
+	flagDescriptor = 1 << 3 // flagDescriptor is set when the sizes follow the compressed data.
+)
+
+// legacy are the compression methods of PKZIP 0.9 and 1.0 that are decompressed by this package.
+var legacy = [...]Method{Shrunk, Reduced1, Reduced2, Reduced3, Reduced4, Imploded}
+
+// Legacy returns true if the compression method is decompressed by this package.
+func (m Method) Legacy() bool {
+	return m >= Shrunk && m <= Imploded
+}
+
+var register sync.Once
+
+// Register registers the decompressors of the legacy methods, Shrunk, Reduced1 to Reduced4
+// and Imploded, with the archive/zip package, so they can be read by any zip.Reader.
+// It is safe to call Register more than once, but it panics if another package
+// has already registered a decompressor for one of these methods.
+func Register() {
+	register.Do(func() {
+		for _, m := range legacy {
+			zip.RegisterDecompressor(uint16(m), Decompressor(m))
+		}
+	})
+}
+
+// RegisterReader registers the decompressors of the legacy methods with a single zip.Reader.
+func RegisterReader(zr *zip.Reader) {
+	if zr == nil {
+		return
+	}
+	for _, m := range legacy {
+		zr.RegisterDecompressor(uint16(m), Decompressor(m))
+	}
+}
+
+// Decompressor returns the archive/zip decompressor of a legacy method, or nil if the method is not supported.
+//
+// A zip.Decompressor is only given the compressed data, but the Reduce and Implode methods
+// have no end marker and Implode has options that are stored in the bit flags.
+// So the decompressor reads the uncompressed size and the bit flags from the local file header
+// that precedes the data, which requires the data to be an io.SectionReader of the archive,
+// as it is when opened using the archive/zip package.
+func Decompressor(m Method) zip.Decompressor {
+	if !m.Legacy() {
+		return nil
+	}
+	return func(r io.Reader) io.ReadCloser {
+		flags, size := localHeader(r)
+		rc, err := NewReader(r, m, flags, size)
+		if err != nil {
+			return &reader{err: err}
+		}
+		return rc
+	}
+}
+
+// Open returns a reader of the decompressed content of a file of a ZIP archive.
+// Unlike the zip.File Open method, it does not require the legacy methods to be registered.
+// The legacy methods are decompressed into memory and the CRC-32 checksum is verified
+// before Open returns, while the other methods are read using the zip.File Open method.
+func Open(f *zip.File) (io.ReadCloser, error) {
+	if f == nil {
+		return nil, fs.ErrInvalid
+	}
+	m := Method(f.Method)
+	if !m.Legacy() {
+		return f.Open()
+	}
+	if f.Flags&flagEncrypted != 0 {
+		return nil, fmt.Errorf("%w: %s is encrypted", ErrMethod, f.Name)
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, fmt.Errorf("pkzip open %s %w", f.Name, err)
+	}
+	size := int64(-1)
+	if f.UncompressedSize64 <= math.MaxInt64 {
+		size = int64(f.UncompressedSize64)
+	}
+	rc, err := NewReader(raw, m, f.Flags, size)
+	if err != nil {
+		return nil, fmt.Errorf("pkzip open %s %w", f.Name, err)
+	}
+	p, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("pkzip open %s %w", f.Name, err)
+	}
+	if crc := crc32.ChecksumIEEE(p); crc != f.CRC32 {
+		return nil, fmt.Errorf("pkzip open %s %w: %08x is %08x", f.Name, zip.ErrChecksum, f.CRC32, crc)
+	}
+	return io.NopCloser(bytes.NewReader(p)), nil
+}
+
+// NewReader returns a reader of the data compressed using a legacy method,
+// Shrunk, Reduced1 to Reduced4 or Imploded.
+// The flags are the general purpose bit flags of the entry,
+// which set the dictionary size and the number of trees of the Imploded method.
+// The size is the uncompressed size of the entry, which is required by the Reduce and Implode methods,
+// or -1 when it is unknown.
+//
+// The data is decompressed into memory on the first read.
+func NewReader(r io.Reader, m Method, flags uint16, size int64) (io.ReadCloser, error) {
+	if r == nil {
+		return nil, fs.ErrInvalid
+	}
+	if !m.Legacy() {
+		return nil, fmt.Errorf("%w: %s", ErrMethod, m)
+	}
+	if size < 0 && m != Shrunk {
+		return nil, fmt.Errorf("%w: %s", ErrSize, m)
+	}
+	br := &bitReader{r: bufio.NewReader(r)}
+	var expand func() ([]byte, error)
+	switch m {
+	case Shrunk:
+		expand = func() ([]byte, error) { return unshrink(br, size) }
+	case Imploded:
+		expand = func() ([]byte, error) { return explode(br, flags, size) }
+	default:
+		factor := int(m-Reduced1) + 1
+		expand = func() ([]byte, error) { return unreduce(br, factor, size) }
+	}
+	return &reader{expand: expand}, nil
+}
+
+// reader decompresses the data into memory on the first read.
+type reader struct {
+	expand func() ([]byte, error)
+	r      *bytes.Reader
+	err    error
+}
+
+// Read implements the io.Reader interface.
+func (r *reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.r == nil {
+		b, err := r.expand()
+		if err != nil {
+			r.err = err
+			return 0, err
+		}
+		r.r = bytes.NewReader(b)
+	}
+	return r.r.Read(p)
+}
+
+// Close implements the io.Closer interface.
+func (r *reader) Close() error {
+	r.r, r.err = nil, fs.ErrClosed
+	return nil
+}
+
+// localHeader returns the bit flags and the uncompressed size of the local file header
+// that precedes the compressed data of an io.SectionReader of a ZIP archive.
+// The size is -1 when the header cannot be found.
+func localHeader(r io.Reader) (uint16, int64) {
+	const (
+		signature  = "PK\x03\x04"
+		headerLen  = 30
+		nameLen    = 1024
+		maxLen     = headerLen + 2*math.MaxUint16
+		descriptor = "PK\x07\x08"
+	)
+	sr, ok := r.(*io.SectionReader)
+	if !ok {
+		return 0, -1
+	}
+	ra, off, n := sr.Outer()
+	// the name and extra field lengths are unknown, so search backwards for the header,
+	// first assuming that they are short
+	for _, length := range []int64{headerLen + nameLen, maxLen} {
+		start := max(off-length, 0)
+		p := make([]byte, off-start)
+		if _, err := ra.ReadAt(p, start); err != nil {
+			return 0, -1
+		}
+		for i := len(p) - headerLen; i >= 0; i-- {
+			h := p[i:]
+			if string(h[:4]) != signature {
+				continue
+			}
+			name, extra := binary.LittleEndian.Uint16(h[26:]), binary.LittleEndian.Uint16(h[28:])
+			if i+headerLen+int(name)+int(extra) != len(p) {
+				continue
+			}
+			flags := binary.LittleEndian.Uint16(h[6:])
+			size := binary.LittleEndian.Uint32(h[22:])
+			if flags&flagDescriptor != 0 {
+				// the data descriptor has an optional signature, the CRC-32 and the sizes
+				d := make([]byte, 16)
+				if m, _ := ra.ReadAt(d, off+n); m < len(d)-4 {
+					return flags, -1
+				}
+				if string(d[:4]) == descriptor {
+					d = d[4:]
+				}
+				size = binary.LittleEndian.Uint32(d[8:])
+			}
+			if size == math.MaxUint32 {
+				return flags, -1
+			}
+			return flags, int64(size)
+		}
+		if start == 0 {
+			break
+		}
+	}
+	return 0, -1
+}
+
+// bitReader reads the bits of the compressed data, starting with the least significant bit of each byte,
+// which is the bit order of all the PKZIP compression methods.
+type bitReader struct {
+	r    io.ByteReader
+	bits uint32 // bits are the buffered bits
+	n    uint   // n is the number of buffered bits
+}
+
+// read returns the value of the next n bits, where n is at most 24.
+// It returns io.EOF when the data ends before the bits.
+func (br *bitReader) read(n uint) (uint32, error) {
+	for br.n < n {
+		b, err := br.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		br.bits |= uint32(b) << br.n
+		br.n += 8
+	}
+	v := br.bits & (1<<n - 1)
+	br.bits >>= n
+	br.n -= n
+	return v, nil
+}
+
+// unexpected returns io.ErrUnexpectedEOF for an io.EOF error, as the data ended too soon.
+func unexpected(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// grow returns an empty slice with the capacity of the uncompressed size, when it is known.
+func grow(size int64) []byte {
+	const limit = 1 << 24
+	return make([]byte, 0, min(max(size, 0), limit))
+}
@@ -0,0 +1,182 @@
+package pkzip_test
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Defacto2/helper/pkzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The fixtures are archives of the ../testdata/PKZ80A1.TXT file.
+// REDUCED.ZIP has an entry for each compression factor, and IMPLODED.ZIP has an entry
+// for each combination of the 4K or 8K dictionary and the two or three trees.
+var fixtures = []string{"SHRUNK.ZIP", "REDUCED.ZIP", "IMPLODED.ZIP"}
+
+// pack returns the values packed using n bits each, starting with the least significant bit.
+func pack(n uint, values ...int) []byte {
+	var (
+		p    []byte
+		bits uint64
+		size uint
+	)
+	for _, v := range values {
+		bits |= uint64(v) << size
+		size += n
+		for size >= 8 {
+			p = append(p, byte(bits))
+			bits >>= 8
+			size -= 8
+		}
+	}
+	if size > 0 {
+		p = append(p, byte(bits))
+	}
+	return p
+}
+
+func ExampleOpen() {
+	zr, err := zip.OpenReader(filepath.Join("testdata", "SHRUNK.ZIP"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		rc, err := pkzip.Open(f)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		scanner := bufio.NewScanner(rc)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				fmt.Printf("%s %s: %s\n", f.Name, pkzip.Method(f.Method), line)
+				break
+			}
+		}
+		rc.Close()
+	}
+	// Output: PKZ80A1.TXT Shrunk: PKZIP (R)   FAST!   Create/Update Utility   Version 2.04g   02-01-93
+}
+
+func TestMethodLegacy(t *testing.T) {
+	t.Parallel()
+	assert.False(t, pkzip.Stored.Legacy())
+	assert.True(t, pkzip.Shrunk.Legacy())
+	assert.True(t, pkzip.Reduced4.Legacy())
+	assert.True(t, pkzip.Imploded.Legacy())
+	assert.False(t, pkzip.Tokenized.Legacy())
+	assert.False(t, pkzip.Deflated.Legacy())
+	assert.Nil(t, pkzip.Decompressor(pkzip.Deflated))
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+	want, err := os.ReadFile(filepath.Join("..", "testdata", "PKZ80A1.TXT"))
+	require.NoError(t, err)
+	count := 0
+	for _, name := range fixtures {
+		zr, err := zip.OpenReader(filepath.Join("testdata", name))
+		require.NoError(t, err)
+		defer zr.Close()
+		for _, f := range zr.File {
+			rc, err := pkzip.Open(f)
+			require.NoError(t, err, f.Name)
+			p, err := io.ReadAll(rc)
+			require.NoError(t, err, f.Name)
+			require.NoError(t, rc.Close())
+			assert.Equal(t, want, p, f.Name)
+			count++
+		}
+	}
+	assert.Equal(t, 9, count)
+
+	_, err = pkzip.Open(nil)
+	require.Error(t, err)
+}
+
+func TestOpenChecksum(t *testing.T) {
+	t.Parallel()
+	zr, err := zip.OpenReader(filepath.Join("testdata", "SHRUNK.ZIP"))
+	require.NoError(t, err)
+	defer zr.Close()
+	f := *zr.File[0]
+	f.CRC32++
+	_, err = pkzip.Open(&f)
+	require.ErrorIs(t, err, zip.ErrChecksum)
+}
+
+func TestRegisterReader(t *testing.T) {
+	t.Parallel()
+	want, err := os.ReadFile(filepath.Join("..", "testdata", "PKZ80A1.TXT"))
+	require.NoError(t, err)
+	for _, name := range fixtures {
+		zr, err := zip.OpenReader(filepath.Join("testdata", name))
+		require.NoError(t, err)
+		defer zr.Close()
+		pkzip.RegisterReader(&zr.Reader)
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			require.NoError(t, err, f.Name)
+			p, err := io.ReadAll(rc)
+			require.NoError(t, err, f.Name)
+			require.NoError(t, rc.Close())
+			assert.Equal(t, want, p, f.Name)
+		}
+	}
+	pkzip.RegisterReader(nil)
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+	pkzip.Register()
+	pkzip.Register()
+	zr, err := zip.OpenReader(filepath.Join("testdata", "IMPLODED.ZIP"))
+	require.NoError(t, err)
+	defer zr.Close()
+	rc, err := zr.File[0].Open()
+	require.NoError(t, err)
+	defer rc.Close()
+	_, err = io.Copy(io.Discard, rc)
+	require.NoError(t, err)
+}
+
+func TestDecompressor(t *testing.T) {
+	t.Parallel()
+	// without the local file header, the reduce and implode methods do not know the size
+	rc := pkzip.Decompressor(pkzip.Imploded)(bytes.NewReader(nil))
+	_, err := io.ReadAll(rc)
+	require.ErrorIs(t, err, pkzip.ErrSize)
+	require.NoError(t, rc.Close())
+
+	rc = pkzip.Decompressor(pkzip.Shrunk)(bytes.NewReader(pack(9, 'H', 'i')))
+	p, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi", string(p))
+	require.NoError(t, rc.Close())
+	_, err = rc.Read(p)
+	require.Error(t, err)
+}
+
+func TestNewReader(t *testing.T) {
+	t.Parallel()
+	_, err := pkzip.NewReader(nil, pkzip.Shrunk, 0, -1)
+	require.Error(t, err)
+	_, err = pkzip.NewReader(bytes.NewReader(nil), pkzip.Deflated, 0, 0)
+	require.ErrorIs(t, err, pkzip.ErrMethod)
+	_, err = pkzip.NewReader(bytes.NewReader(nil), pkzip.Reduced2, 0, -1)
+	require.ErrorIs(t, err, pkzip.ErrSize)
+
+	rc, err := pkzip.NewReader(bytes.NewReader(nil), pkzip.Imploded, 0, 10)
+	require.NoError(t, err)
+	_, err = io.ReadAll(rc)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,162 @@
+package pkzip
+
+import (
+	"fmt"
+)
+
+// The Shannon-Fano trees of the Implode method.
+const (
+	literals   = 256 // literals is the number of values of the literal tree.
+	lengths    = 64  // lengths is the number of values of the length tree.
+	distances  = 64  // distances is the number of values of the distance tree.
+	maxCodeLen = 16  // maxCodeLen is the maximum bit length of a code.
+)
+
+// explode returns the data compressed using the Implode method of PKZIP 1.0,
+// which is a sliding dictionary of 4K or 8K bytes with the Shannon-Fano coding of the
+// lengths and distances, and optionally of the literal bytes.
+// The flags are the general purpose bit flags and the size is the uncompressed size,
+// as the compressed data has no end marker.
+func explode(br *bitReader, flags uint16, size int64) ([]byte, error) {
+	var literal *tree
+	minMatch := 2
+	if flags&flagTrees != 0 {
+		t, err := readTree(br, literals)
+		if err != nil {
+			return nil, fmt.Errorf("implode literal %w", err)
+		}
+		literal, minMatch = &t, 3
+	}
+	length, err := readTree(br, lengths)
+	if err != nil {
+		return nil, fmt.Errorf("implode length %w", err)
+	}
+	distance, err := readTree(br, distances)
+	if err != nil {
+		return nil, fmt.Errorf("implode distance %w", err)
+	}
+	lowBits := uint(6)
+	if flags&flagDictionary != 0 {
+		lowBits = 7
+	}
+	out := grow(size)
+	for int64(len(out)) < size {
+		isLiteral, err := br.read(1)
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		if isLiteral == 1 {
+			var c int
+			if literal != nil {
+				c, err = literal.decode(br)
+			} else {
+				var v uint32
+				v, err = br.read(8)
+				c = int(v)
+			}
+			if err != nil {
+				return nil, unexpected(err)
+			}
+			out = append(out, byte(c))
+			continue
+		}
+		low, err := br.read(lowBits)
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		high, err := distance.decode(br)
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		n, err := length.decode(br)
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		if n == lengths-1 {
+			extra, err := br.read(8)
+			if err != nil {
+				return nil, unexpected(err)
+			}
+			n += int(extra)
+		}
+		// any bytes before the start of the data are zeros
+		dist := high<<lowBits + int(low) + 1
+		for range n + minMatch {
+			var b byte
+			if i := len(out) - dist; i >= 0 {
+				b = out[i]
+			}
+			out = append(out, b)
+		}
+	}
+	return out[:size], nil
+}
+
+// tree is a Shannon-Fano tree of the Implode method, which is a canonical prefix code
+// that is stored using the bit lengths of the values.
+type tree struct {
+	count  [maxCodeLen + 1]int // count is the number of codes of each bit length
+	values []int               // values are ordered by their codes
+}
+
+// readTree returns the tree of n values that is stored at the start of the compressed data.
+// The first byte is the number of bytes of the tree minus one, and each byte that follows
+// has the bit length minus one of the values in the low nibble,
+// and the number of values minus one using that bit length in the high nibble.
+func readTree(br *bitReader, n int) (tree, error) {
+	b, err := br.read(8)
+	if err != nil {
+		return tree{}, unexpected(err)
+	}
+	bitLens := make([]int, 0, n)
+	for range b + 1 {
+		v, err := br.read(8)
+		if err != nil {
+			return tree{}, unexpected(err)
+		}
+		bitLen, count := int(v&0x0f)+1, int(v>>4)+1
+		if len(bitLens)+count > n {
+			return tree{}, fmt.Errorf("%w: tree has more than %d values", ErrData, n)
+		}
+		for range count {
+			bitLens = append(bitLens, bitLen)
+		}
+	}
+	if len(bitLens) != n {
+		return tree{}, fmt.Errorf("%w: tree has %d of %d values", ErrData, len(bitLens), n)
+	}
+	t := tree{values: make([]int, 0, n)}
+	for _, bitLen := range bitLens {
+		t.count[bitLen]++
+	}
+	for bitLen := 1; bitLen <= maxCodeLen; bitLen++ {
+		for value, l := range bitLens {
+			if l == bitLen {
+				t.values = append(t.values, value)
+			}
+		}
+	}
+	return t, nil
+}
+
+// decode returns the value of the next code of the compressed data.
+// The codes are read one bit at a time, starting with the most significant bit,
+// and PKZIP stores the codes with every bit inverted.
+func (t *tree) decode(br *bitReader) (int, error) {
+	code, first, index := 0, 0, 0
+	for bitLen := 1; bitLen <= maxCodeLen; bitLen++ {
+		b, err := br.read(1)
+		if err != nil {
+			return 0, err
+		}
+		code |= int(b ^ 1)
+		count := t.count[bitLen]
+		if code-first < count {
+			return t.values[index+code-first], nil
+		}
+		index += count
+		first = (first + count) << 1
+		code <<= 1
+	}
+	return 0, fmt.Errorf("%w: implode code is not in the tree", ErrData)
+}
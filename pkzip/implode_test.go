@@ -0,0 +1,28 @@
+package pkzip_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/Defacto2/helper/pkzip"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImplodeTrees(t *testing.T) {
+	t.Parallel()
+	// the length tree is 16 values too short, as each byte is 16 values of 6 bits
+	p := []byte{2, 0xf5, 0xf5, 0xf5}
+	rc, err := pkzip.NewReader(bytes.NewReader(p), pkzip.Imploded, 0, 1)
+	require.NoError(t, err)
+	_, err = io.ReadAll(rc)
+	require.ErrorIs(t, err, pkzip.ErrData)
+
+	// the literal tree has more than 256 values
+	p = bytes.Repeat([]byte{0xf7}, 18)
+	p[0] = 16
+	rc, err = pkzip.NewReader(bytes.NewReader(p), pkzip.Imploded, 4, 1)
+	require.NoError(t, err)
+	_, err = io.ReadAll(rc)
+	require.ErrorIs(t, err, pkzip.ErrData)
+}
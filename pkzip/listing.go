@@ -0,0 +1,193 @@
+package pkzip
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	ErrEntries = errors.New("pkzip listing has no entries")
+	ErrField   = errors.New("pkzip listing field is invalid")
+)
+
+// Entry is a file of a ZIP archive listed by PKZIP or PKUNZIP.
+type Entry struct {
+	Name             string    // Name is the filename of the entry.
+	Text             bool      // Text is true when PKZIP detected the file type as text.
+	Attributes       string    // Attributes are the MS-DOS file attributes as shown by PKZIP, such as "--w-".
+	Modified         time.Time // Modified is the date and time of the file, which has no time zone.
+	Method           Method    // Method is the compression method.
+	MethodName       string    // MethodName is the compression method as shown by PKZIP.
+	CompressedSize   int64     // CompressedSize is the size of the compressed data in bytes.
+	UncompressedSize int64     // UncompressedSize is the size of the file in bytes.
+	CRC32            uint32    // CRC32 is the checksum of the uncompressed data.
+	CreatedBy        string    // CreatedBy is the program and system that created the entry, such as "PKZIP: 1.0 under MS-DOS".
+	NeededToExtract  string    // NeededToExtract is the program version needed to extract the entry, such as "PKUNZIP: 1.0".
+}
+
+// Listing is the parsed output of a PKZIP -v or PKUNZIP -v command.
+type Listing struct {
+	Program string   // Program is the name of the program that created the listing, such as "PKZIP".
+	Version string   // Version is the version of the program, such as "2.04g".
+	Archive string   // Archive is the path of the ZIP archive that was listed.
+	Notes   []string // Notes are the bullet point messages of the program, such as "80486 CPU detected."
+	Entries []Entry  // Entries are the files of the archive in the listed order.
+}
+
+// Parse returns the listing of the verbose output of the PKZIP and PKUNZIP 1.x and 2.x programs.
+// The text is expected to use the CP-437 encoding, which is decoded to UTF-8.
+//
+// Two formats are understood, the "Filename: TEST.ANS" fields of the PKZIP -v command,
+// and the table of the PKUNZIP -v command that lists an entry on each line.
+func Parse(r io.Reader) (Listing, error) {
+	var l Listing
+	scanner := bufio.NewScanner(charmap.CodePage437.NewDecoder().Reader(r))
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimRight(scanner.Text(), " \r\x1a")
+		if err := l.line(line); err != nil {
+			return Listing{}, fmt.Errorf("pkzip parse line %d %w", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Listing{}, fmt.Errorf("pkzip parse %w", err)
+	}
+	if len(l.Entries) == 0 {
+		return Listing{}, ErrEntries
+	}
+	return l, nil
+}
+
+// headerRe matches the banner of the PKWARE programs, such as "PKZIP (R)   FAST!   ... Version 2.04g".
+var headerRe = regexp.MustCompile(`^(PK[A-Z]+)\b.*\bVersion\s+(\S+)`)
+
+// line parses a line of a listing.
+func (l *Listing) line(line string) error {
+	const bullet = "■"
+	trim := strings.TrimSpace(line)
+	switch {
+	case trim == "":
+		return nil
+	case strings.HasPrefix(trim, bullet):
+		l.Notes = append(l.Notes, strings.TrimSpace(strings.TrimPrefix(trim, bullet)))
+		return nil
+	}
+	if m := headerRe.FindStringSubmatch(trim); m != nil && l.Program == "" {
+		l.Program, l.Version = m[1], m[2]
+		return nil
+	}
+	if ok, err := l.row(trim); ok {
+		return err
+	}
+	key, val, ok := strings.Cut(line, ":")
+	if !ok {
+		return nil
+	}
+	return l.field(strings.TrimSpace(key), strings.TrimSpace(val))
+}
+
+// field parses a "Key: Value" field of the PKZIP -v listing.
+// A Filename field starts a new entry and the unknown fields are ignored.
+func (l *Listing) field(key, val string) error {
+	if key == "Searching ZIP" {
+		l.Archive = val
+		return nil
+	}
+	if key == "Filename" {
+		l.Entries = append(l.Entries, Entry{Name: val})
+		return nil
+	}
+	if len(l.Entries) == 0 {
+		return nil
+	}
+	e := &l.Entries[len(l.Entries)-1]
+	var err error
+	switch key {
+	case "File type":
+		e.Text = strings.EqualFold(val, "text")
+	case "Attributes":
+		e.Attributes = val
+	case "Date and Time":
+		e.Modified, err = modified(val)
+	case "Compression Method":
+		e.MethodName = val
+		e.Method, _ = ParseMethod(val)
+	case "Compressed Size":
+		e.CompressedSize, err = strconv.ParseInt(val, 10, 64)
+	case "Uncompressed Size":
+		e.UncompressedSize, err = strconv.ParseInt(val, 10, 64)
+	case "32 bit CRC value":
+		e.CRC32, err = crc(val)
+	case "Created by":
+		e.CreatedBy = strings.Join(strings.Fields(val), " ")
+	case "Needed to extract":
+		e.NeededToExtract = strings.Join(strings.Fields(val), " ")
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s: %q", ErrField, key, val)
+	}
+	return nil
+}
+
+// rowRe matches an entry of the PKUNZIP -v table, which is the length, method, size, ratio,
+// date, time, CRC-32, optional attributes and the name.
+var rowRe = regexp.MustCompile(`^(\d+)\s+(\S+)\s+(\d+)\s+\d+%\s+(\d\d-\d\d-\d\d)\s+(\d\d:\d\d)\s+([0-9a-fA-F]{8})\s+(?:([-a-z*]{4})\s+)?(\S.*)$`)
+
+// row parses an entry of the PKUNZIP -v table and returns true if the line is an entry.
+func (l *Listing) row(line string) (bool, error) {
+	m := rowRe.FindStringSubmatch(line)
+	if m == nil {
+		return false, nil
+	}
+	e := Entry{
+		Name:       m[8],
+		Attributes: m[7],
+		MethodName: m[2],
+	}
+	e.Method, _ = ParseMethod(m[2])
+	var err error
+	if e.UncompressedSize, err = strconv.ParseInt(m[1], 10, 64); err != nil {
+		return true, fmt.Errorf("%w: length: %q", ErrField, m[1])
+	}
+	if e.CompressedSize, err = strconv.ParseInt(m[3], 10, 64); err != nil {
+		return true, fmt.Errorf("%w: size: %q", ErrField, m[3])
+	}
+	if e.Modified, err = time.Parse("01-02-06 15:04", m[4]+" "+m[5]); err != nil {
+		return true, fmt.Errorf("%w: date: %q", ErrField, m[4]+" "+m[5])
+	}
+	if e.CRC32, err = crc(m[6]); err != nil {
+		return true, fmt.Errorf("%w: crc-32: %q", ErrField, m[6])
+	}
+	l.Entries = append(l.Entries, e)
+	return true, nil
+}
+
+// modified returns the time of a PKZIP date and time, such as "Sep 19,2012  14:21:52".
+func modified(s string) (time.Time, error) {
+	s = strings.Join(strings.Fields(strings.ReplaceAll(s, ",", ", ")), " ")
+	var err error
+	for _, layout := range []string{"Jan 2, 2006 15:04:05", "Jan 2, 2006 15:04", "01-02-06 15:04:05", "01-02-06 15:04"} {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// crc returns the value of a hexadecimal CRC-32 checksum.
+func crc(s string) (uint32, error) {
+	i, err := strconv.ParseUint(s, 16, 32)
+	return uint32(i), err
+}
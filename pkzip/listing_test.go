@@ -0,0 +1,98 @@
+package pkzip_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Defacto2/helper/pkzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleParse() {
+	f, _ := os.Open("../testdata/PKZ80A1.TXT")
+	defer f.Close()
+	l, _ := pkzip.Parse(f)
+	e := l.Entries[0]
+	fmt.Println(l.Program, l.Version, len(l.Entries))
+	fmt.Printf("%s %s %d %08x\n", e.Name, e.Method, e.UncompressedSize, e.CRC32)
+	// Output:
+	// PKZIP 2.04g 15
+	// TEST.ANS Shrunk 68 5ce2f707
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	f, err := os.Open("../testdata/PKZ80A1.TXT")
+	require.NoError(t, err)
+	defer f.Close()
+	l, err := pkzip.Parse(f)
+	require.NoError(t, err)
+	assert.Equal(t, "PKZIP", l.Program)
+	assert.Equal(t, "2.04g", l.Version)
+	assert.Equal(t, "C:/ZIP/PKZ80A1.ZIP", l.Archive)
+	assert.Equal(t, []string{"80486 CPU detected.", "EMS version 4.00 detected.", "XMS version 3.00 detected."}, l.Notes)
+	require.Len(t, l.Entries, 15)
+
+	assert.Equal(t, pkzip.Entry{
+		Name:             "TEST.BMP",
+		Text:             false,
+		Attributes:       "--w-",
+		Modified:         time.Date(2012, 9, 19, 14, 11, 24, 0, time.UTC),
+		Method:           pkzip.Shrunk,
+		MethodName:       "Shrunk",
+		CompressedSize:   4446,
+		UncompressedSize: 750054,
+		CRC32:            0x64c6e850,
+		CreatedBy:        "PKZIP: 1.0 under MS-DOS",
+		NeededToExtract:  "PKUNZIP: 1.0",
+	}, l.Entries[2])
+	asc := l.Entries[1]
+	assert.True(t, asc.Text)
+	assert.Equal(t, pkzip.Stored, asc.Method)
+	assert.Equal(t, "TEST~1.JPE", l.Entries[14].Name)
+	assert.Equal(t, int64(1029891), l.Entries[6].CompressedSize)
+}
+
+func TestParseTable(t *testing.T) {
+	t.Parallel()
+	const s = "PKUNZIP (R)    FAST!    Extract Utility    Version 1.10    03-15-90\r\n" +
+		"Searching ZIP: BBSAD.ZIP\r\n\r\n" +
+		" Length  Method   Size  Ratio   Date    Time    CRC-32  Attr  Name\r\n" +
+		" ------  ------   ----- -----   ----    ----   -------- ----  ----\r\n" +
+		"     68  Shrunk      63   8%  09-19-91  14:21  5ce2f707 --w-  TEST.ANS\r\n" +
+		"   2048  Reduce3    900  57%  01-02-90  08:05  0000abcd --w*  CAF\x90.TXT\r\n" +
+		"     13  Stored      13   0%  12-31-89  23:59  7ef91c5d       README\r\n" +
+		" ------          ------  ---                                  -------\r\n" +
+		"   2129             976  55%                                        3\r\n"
+	l, err := pkzip.Parse(strings.NewReader(s))
+	require.NoError(t, err)
+	assert.Equal(t, "PKUNZIP", l.Program)
+	assert.Equal(t, "1.10", l.Version)
+	assert.Equal(t, "BBSAD.ZIP", l.Archive)
+	require.Len(t, l.Entries, 3)
+	e := l.Entries[1]
+	assert.Equal(t, "CAFÉ.TXT", e.Name)
+	assert.Equal(t, pkzip.Reduced3, e.Method)
+	assert.Equal(t, "Reduce3", e.MethodName)
+	assert.Equal(t, int64(2048), e.UncompressedSize)
+	assert.Equal(t, int64(900), e.CompressedSize)
+	assert.Equal(t, uint32(0xabcd), e.CRC32)
+	assert.Equal(t, "--w*", e.Attributes)
+	assert.Equal(t, time.Date(1990, 1, 2, 8, 5, 0, 0, time.UTC), e.Modified)
+	assert.Equal(t, "README", l.Entries[2].Name)
+	assert.Empty(t, l.Entries[2].Attributes)
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+	_, err := pkzip.Parse(strings.NewReader("PKZIP (R) Version 2.04g\n\nNo files found.\n"))
+	require.ErrorIs(t, err, pkzip.ErrEntries)
+
+	_, err = pkzip.Parse(strings.NewReader("          Filename: A.TXT\n 32 bit CRC value: xyz\n"))
+	require.ErrorIs(t, err, pkzip.ErrField)
+	require.ErrorContains(t, err, "line 2")
+}
@@ -0,0 +1,69 @@
+// Package pkzip reads the information of the ZIP archives created by the PKWARE PKZIP
+// and PKUNZIP programs for MS-DOS, which were the most common archivers of the BBS era.
+//
+// It also decompresses the legacy Shrink, Reduce and Implode methods of PKZIP 0.9 and 1.0,
+// which are not supported by the archive/zip package, see Open and Register.
+package pkzip
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Method is the compression method of a ZIP archive entry.
+type Method uint16
+
+const (
+	Stored    Method = 0 // Stored is an uncompressed entry.
+	Shrunk    Method = 1 // Shrunk is the dynamic LZW compression of PKZIP 0.9 and 1.0.
+	Reduced1  Method = 2 // Reduced1 is the probabilistic compression of PKZIP 0.9 using factor 1.
+	Reduced2  Method = 3 // Reduced2 is the probabilistic compression of PKZIP 0.9 using factor 2.
+	Reduced3  Method = 4 // Reduced3 is the probabilistic compression of PKZIP 0.9 using factor 3.
+	Reduced4  Method = 5 // Reduced4 is the probabilistic compression of PKZIP 0.9 using factor 4.
+	Imploded  Method = 6 // Imploded is the Shannon-Fano and sliding dictionary compression of PKZIP 1.0.
+	Tokenized Method = 7 // Tokenized is a reserved method that was never used by PKZIP.
+	Deflated  Method = 8 // Deflated is the compression of PKZIP 2.0 that is supported by the archive/zip package.
+	Deflate64 Method = 9 // Deflate64 is the enhanced deflate compression of PKZIP for Windows.
+)
+
+// methods are the names of the compression methods as shown by PKZIP.
+var methods = [...]string{
+	"Stored", "Shrunk", "Reduced1", "Reduced2", "Reduced3", "Reduced4",
+	"Imploded", "Tokenized", "Deflated", "Deflate64",
+}
+
+// String returns the name of the compression method.
+func (m Method) String() string {
+	if int(m) < len(methods) {
+		return methods[m]
+	}
+	return "Method(" + strconv.Itoa(int(m)) + ")"
+}
+
+// ParseMethod returns the compression method of a name shown by PKZIP and PKUNZIP,
+// such as "Shrunk", "Reduce3", "Reduced(3)", "Implode" or "DeflatN",
+// or false if the name is not known.
+func ParseMethod(name string) (Method, bool) {
+	s := strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case strings.HasPrefix(s, "stor"):
+		return Stored, true
+	case strings.HasPrefix(s, "shrunk"), strings.HasPrefix(s, "shrink"):
+		return Shrunk, true
+	case strings.HasPrefix(s, "reduc"):
+		i := strings.IndexAny(s, "1234")
+		if i < 0 {
+			return 0, false
+		}
+		return Reduced1 + Method(s[i]-'1'), true
+	case strings.HasPrefix(s, "implod"):
+		return Imploded, true
+	case strings.HasPrefix(s, "token"):
+		return Tokenized, true
+	case strings.HasPrefix(s, "deflate64"), strings.HasPrefix(s, "enh"):
+		return Deflate64, true
+	case strings.HasPrefix(s, "deflat"):
+		return Deflated, true
+	}
+	return 0, false
+}
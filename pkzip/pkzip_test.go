@@ -0,0 +1,37 @@
+package pkzip_test
+
+import (
+	"testing"
+
+	"github.com/Defacto2/helper/pkzip"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethod(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "Shrunk", pkzip.Shrunk.String())
+	assert.Equal(t, "Reduced3", pkzip.Reduced3.String())
+	assert.Equal(t, "Method(99)", pkzip.Method(99).String())
+	tests := []struct {
+		name   string
+		expect pkzip.Method
+	}{
+		{"Stored ", pkzip.Stored},
+		{"Shrunk", pkzip.Shrunk},
+		{"Reduce1", pkzip.Reduced1},
+		{"Reduced(4)", pkzip.Reduced4},
+		{"Implode", pkzip.Imploded},
+		{"DeflatN", pkzip.Deflated},
+		{"DeflatX", pkzip.Deflated},
+		{"Deflate64", pkzip.Deflate64},
+	}
+	for _, tt := range tests {
+		m, ok := pkzip.ParseMethod(tt.name)
+		assert.True(t, ok, tt.name)
+		assert.Equal(t, tt.expect, m, tt.name)
+	}
+	_, ok := pkzip.ParseMethod("Reduced")
+	assert.False(t, ok)
+	_, ok = pkzip.ParseMethod("Squeezed")
+	assert.False(t, ok)
+}
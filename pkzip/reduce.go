@@ -0,0 +1,106 @@
+package pkzip
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// reduceDLE is the byte of the Reduce method that starts a repeated sequence of bytes.
+const reduceDLE = 0x90
+
+// unreduce returns the data compressed using the Reduce method with a compression factor of 1 to 4,
+// which is the probabilistic compression of PKZIP 0.9 that encodes each byte using the set of bytes
+// that most often follow the previous byte, combined with the run-length encoding of repeated sequences.
+// The size is the uncompressed size, as the compressed data has no end marker.
+func unreduce(br *bitReader, factor int, size int64) ([]byte, error) {
+	var followers [256][]byte
+	for i := len(followers) - 1; i >= 0; i-- {
+		n, err := br.read(6)
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		followers[i] = make([]byte, n)
+		for j := range followers[i] {
+			v, err := br.read(8)
+			if err != nil {
+				return nil, unexpected(err)
+			}
+			followers[i][j] = byte(v)
+		}
+	}
+	mask := 0x7f >> (factor - 1) // mask are the bits of the length in the first byte of a sequence
+	out := grow(size)
+	var (
+		last      byte // last is the previous byte of the compressed data
+		state     int  // state is the number of bytes read of a repeated sequence
+		v, length int
+	)
+	for int64(len(out)) < size {
+		c, err := follower(br, followers[last])
+		if err != nil {
+			return nil, err
+		}
+		last = c
+		switch state {
+		case 0:
+			if c == reduceDLE {
+				state = 1
+				continue
+			}
+			out = append(out, c)
+		case 1:
+			if c == 0 {
+				out = append(out, reduceDLE)
+				state = 0
+				continue
+			}
+			v, length = int(c), int(c)&mask
+			state = 3
+			if length == mask {
+				state = 2
+			}
+		case 2:
+			length += int(c)
+			state = 3
+		case 3:
+			// the distance uses the remaining bits of the first byte and all the bits of the last byte,
+			// and any bytes before the start of the data are zeros
+			distance := (v>>(8-factor))<<8 + int(c) + 1
+			for range length + 3 {
+				var b byte
+				if i := len(out) - distance; i >= 0 {
+					b = out[i]
+				}
+				out = append(out, b)
+			}
+			state = 0
+		}
+	}
+	return out[:size], nil
+}
+
+// follower returns the next byte of the Reduce compressed data, which is either
+// an index of the follower set of the previous byte or a literal byte.
+func follower(br *bitReader, set []byte) (byte, error) {
+	if len(set) > 0 {
+		literal, err := br.read(1)
+		if err != nil {
+			return 0, unexpected(err)
+		}
+		if literal == 0 {
+			i, err := br.read(uint(max(bits.Len(uint(len(set)-1)), 1)))
+			if err != nil {
+				return 0, unexpected(err)
+			}
+			if int(i) >= len(set) {
+				return 0, fmt.Errorf("%w: reduce follower %d of %d", ErrData, i, len(set))
+			}
+			return set[i], nil
+		}
+	}
+	v, err := br.read(8)
+	if err != nil {
+		return 0, unexpected(err)
+	}
+	return byte(v), nil
+}
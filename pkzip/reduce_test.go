@@ -0,0 +1,34 @@
+package pkzip_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/Defacto2/helper/pkzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReduce(t *testing.T) {
+	t.Parallel()
+	// the 256 empty follower sets use 6 bits each, so every byte that follows is a literal
+	sets := make([]byte, 256*6/8)
+	const dle = 0x90
+	p := append(sets, pack(8,
+		dle, 1, 9, // repeat 4 bytes from a distance of 10, before the start of the data
+		'a', 'b',
+		dle, 3, 1, // repeat 6 bytes from a distance of 2
+		dle, 0, // the DLE byte
+	)...)
+	rc, err := pkzip.NewReader(bytes.NewReader(p), pkzip.Reduced1, 0, 13)
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "\x00\x00\x00\x00abababab\x90", string(got))
+
+	rc, err = pkzip.NewReader(bytes.NewReader(p), pkzip.Reduced1, 0, 14)
+	require.NoError(t, err)
+	_, err = io.ReadAll(rc)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,127 @@
+package pkzip
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The codes of the Shrink method.
+const (
+	shrinkControl = 256     // shrinkControl is followed by a command code.
+	shrinkGrow    = 1       // shrinkGrow is the command to increase the code size by one bit.
+	shrinkClear   = 2       // shrinkClear is the command to free the codes that are not a prefix.
+	shrinkFirst   = 257     // shrinkFirst is the first code of the strings table.
+	shrinkMinBits = 9       // shrinkMinBits is the initial code size.
+	shrinkMaxBits = 13      // shrinkMaxBits is the maximum code size.
+	shrinkCodes   = 1 << 13 // shrinkCodes is the number of codes of the maximum code size.
+	shrinkFree    = -1      // shrinkFree is the prefix of a code that is not in use.
+)
+
+// unshrink returns the data compressed using the Shrink method,
+// which is the dynamic LZW compression with partial clearing of PKZIP 0.9 and 1.0.
+// The size is the uncompressed size, or -1 to decompress until the end of the data.
+//
+// The strings of the codes are kept as positions in the decompressed data, rather than
+// as a chain of prefix codes, because the prefix of a new code may have been freed by
+// a partial clear.
+func unshrink(br *bitReader, size int64) ([]byte, error) {
+	table := make([]shrinkEntry, shrinkCodes)
+	queue := make([]int, 0, shrinkCodes-shrinkFirst) // queue are the free codes in ascending order
+	for code := shrinkFirst; code < shrinkCodes; code++ {
+		table[code].prefix = shrinkFree
+		queue = append(queue, code)
+	}
+	out := grow(size)
+	bits := uint(shrinkMinBits)
+	v, err := br.read(bits)
+	if errors.Is(err, io.EOF) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if v >= shrinkControl {
+		return nil, fmt.Errorf("%w: shrink first code %d is not a byte", ErrData, v)
+	}
+	out = append(out, byte(v))
+	prev, prevPos, prevLen := int(v), 0, 1
+	for size < 0 || int64(len(out)) < size {
+		v, err := br.read(bits)
+		if errors.Is(err, io.EOF) && size < 0 {
+			break
+		}
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		code := int(v)
+		if code == shrinkControl {
+			cmd, err := br.read(bits)
+			if err != nil {
+				return nil, unexpected(err)
+			}
+			switch cmd {
+			case shrinkGrow:
+				bits++
+				if bits > shrinkMaxBits {
+					return nil, fmt.Errorf("%w: shrink code size %d", ErrData, bits)
+				}
+			case shrinkClear:
+				queue = partialClear(table, queue[:0])
+			default:
+				return nil, fmt.Errorf("%w: shrink command %d", ErrData, cmd)
+			}
+			continue
+		}
+		pos := len(out)
+		switch {
+		case code < shrinkControl:
+			out = append(out, byte(code))
+		case table[code].prefix != shrinkFree:
+			e := table[code]
+			out = append(out, out[e.pos:e.pos+e.len]...)
+		case len(queue) > 0 && code == queue[0]:
+			// the code is the next free code, which is the previous string followed by its first byte
+			out = append(out, out[prevPos:prevPos+prevLen]...)
+			out = append(out, out[prevPos])
+		default:
+			return nil, fmt.Errorf("%w: shrink code %d is free", ErrData, code)
+		}
+		// the new string is the previous string followed by the first byte of the current string,
+		// which are next to each other in the decompressed data
+		if len(queue) > 0 {
+			table[queue[0]] = shrinkEntry{prefix: prev, pos: prevPos, len: prevLen + 1}
+			queue = queue[1:]
+		}
+		prev, prevPos, prevLen = code, pos, len(out)-pos
+	}
+	if size >= 0 && int64(len(out)) > size {
+		out = out[:size]
+	}
+	return out, nil
+}
+
+// shrinkEntry is a code of the strings table of the Shrink method.
+type shrinkEntry struct {
+	prefix int // prefix is the code of the string without its last byte, or shrinkFree
+	pos    int // pos is the position of the string in the decompressed data
+	len    int // len is the length of the string
+}
+
+// partialClear frees the codes of the table that are not the prefix of another code,
+// and appends the free codes to the queue in ascending order.
+func partialClear(table []shrinkEntry, queue []int) []int {
+	parent := make([]bool, len(table))
+	for code := shrinkFirst; code < len(table); code++ {
+		if p := table[code].prefix; p != shrinkFree {
+			parent[p] = true
+		}
+	}
+	for code := shrinkFirst; code < len(table); code++ {
+		if !parent[code] {
+			table[code].prefix = shrinkFree
+			queue = append(queue, code)
+		}
+	}
+	return queue
+}
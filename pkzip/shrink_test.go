@@ -0,0 +1,57 @@
+package pkzip_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/Defacto2/helper/pkzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unshrink(t *testing.T, p []byte, size int64) ([]byte, error) {
+	t.Helper()
+	rc, err := pkzip.NewReader(bytes.NewReader(p), pkzip.Shrunk, 0, size)
+	require.NoError(t, err)
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func TestShrink(t *testing.T) {
+	t.Parallel()
+	// codes 257 and 258 are used before they are added to the table
+	p, err := unshrink(t, pack(9, 'a', 257, 258), -1)
+	require.NoError(t, err)
+	assert.Equal(t, "aaaaaa", string(p))
+
+	p, err = unshrink(t, pack(9, 'a', 257, 258), 4)
+	require.NoError(t, err)
+	assert.Equal(t, "aaaa", string(p))
+
+	// the partial clear frees the codes 257 and 258, so they are added again
+	p, err = unshrink(t, pack(9, 'a', 'b', 'c', 256, 2, 'd', 258, 257), -1)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdddcd", string(p))
+
+	// the code size increases to 10 bits after eight codes, which end on a byte boundary
+	p, err = unshrink(t, append(pack(9, 'a', 'b', 'c', 'd', 'e', 'f', 256, 1), pack(10, 257, 'g')...), -1)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdefabg", string(p))
+
+	p, err = unshrink(t, nil, -1)
+	require.NoError(t, err)
+	assert.Empty(t, p)
+}
+
+func TestShrinkErrors(t *testing.T) {
+	t.Parallel()
+	_, err := unshrink(t, pack(9, 256), -1)
+	require.ErrorIs(t, err, pkzip.ErrData)
+	_, err = unshrink(t, pack(9, 'a', 256, 3), -1)
+	require.ErrorIs(t, err, pkzip.ErrData)
+	_, err = unshrink(t, pack(9, 'a', 300), -1)
+	require.ErrorIs(t, err, pkzip.ErrData)
+	_, err = unshrink(t, pack(9, 'a', 'b'), 10)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,69 @@
+package pkzip
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	ErrMismatch = errors.New("pkzip listing does not match the archive")
+	ErrMissing  = errors.New("pkzip listing entry is missing from the archive")
+)
+
+// Verify cross-checks the entries of the listing against the files of the ZIP archive,
+// comparing the CRC-32 checksums, the sizes and the compression methods.
+// It returns the joined ErrMismatch and ErrMissing errors of every entry that differs,
+// or nil when the listing describes the archive.
+//
+// The names of the files that are not UTF-8 encoded are decoded as CP-437,
+// the same as the names of the listing.
+func (l Listing) Verify(zr *zip.Reader) error {
+	if zr == nil {
+		return ErrMissing
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[Name(f)] = f
+	}
+	var errs []error
+	for _, e := range l.Entries {
+		f, ok := files[e.Name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrMissing, e.Name))
+			continue
+		}
+		if f.CRC32 != e.CRC32 {
+			errs = append(errs, fmt.Errorf("%w: %s crc-32 %08x is %08x", ErrMismatch, e.Name, e.CRC32, f.CRC32))
+		}
+		if f.CompressedSize64 != uint64(e.CompressedSize) {
+			errs = append(errs, fmt.Errorf("%w: %s compressed size %d is %d",
+				ErrMismatch, e.Name, e.CompressedSize, f.CompressedSize64))
+		}
+		if f.UncompressedSize64 != uint64(e.UncompressedSize) {
+			errs = append(errs, fmt.Errorf("%w: %s uncompressed size %d is %d",
+				ErrMismatch, e.Name, e.UncompressedSize, f.UncompressedSize64))
+		}
+		if f.Method != uint16(e.Method) {
+			errs = append(errs, fmt.Errorf("%w: %s method %s is %s", ErrMismatch, e.Name, e.Method, Method(f.Method)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Name returns the name of the file of a ZIP archive decoded to UTF-8.
+// The archive/zip package returns the names of the MS-DOS archives as raw bytes,
+// so the names that are not marked and valid as UTF-8 are decoded as CP-437.
+func Name(f *zip.File) string {
+	if !f.NonUTF8 && utf8.ValidString(f.Name) {
+		return f.Name
+	}
+	s, err := charmap.CodePage437.NewDecoder().String(f.Name)
+	if err != nil {
+		return f.Name
+	}
+	return s
+}
@@ -0,0 +1,63 @@
+package pkzip_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/Defacto2/helper/pkzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// archive returns a ZIP archive of stored files with MS-DOS CP-437 names.
+func archive(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for name, data := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, NonUTF8: true})
+		require.NoError(t, err)
+		_, err = w.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return zr
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+	zr := archive(t, map[string]string{"README": "Hello world\r\n", "CAF\x90.TXT": "Café"})
+	l := pkzip.Listing{Entries: []pkzip.Entry{
+		{Name: "README", Method: pkzip.Stored, CompressedSize: 13, UncompressedSize: 13, CRC32: crc32.ChecksumIEEE([]byte("Hello world\r\n"))},
+		{Name: "CAFÉ.TXT", Method: pkzip.Stored, CompressedSize: 5, UncompressedSize: 5, CRC32: crc32.ChecksumIEEE([]byte("Café"))},
+	}}
+	require.NoError(t, l.Verify(zr))
+
+	l.Entries[0].CRC32 = 0x5ce2f707
+	l.Entries[1].Method = pkzip.Shrunk
+	l.Entries[1].CompressedSize = 4
+	l.Entries = append(l.Entries, pkzip.Entry{Name: "MISSING.TXT"})
+	err := l.Verify(zr)
+	require.ErrorIs(t, err, pkzip.ErrMismatch)
+	require.ErrorIs(t, err, pkzip.ErrMissing)
+	s := err.Error()
+	assert.Contains(t, s, "README crc-32 5ce2f707 is")
+	assert.Contains(t, s, "CAFÉ.TXT compressed size 4 is 5")
+	assert.Contains(t, s, "CAFÉ.TXT method Shrunk is Stored")
+	assert.Contains(t, s, "MISSING.TXT")
+	assert.Equal(t, 4, strings.Count(s, "\n")+1)
+
+	require.ErrorIs(t, l.Verify(nil), pkzip.ErrMissing)
+}
+
+func TestName(t *testing.T) {
+	t.Parallel()
+	zr := archive(t, map[string]string{"CAF\x90.TXT": ""})
+	assert.Equal(t, "CAFÉ.TXT", pkzip.Name(zr.File[0]))
+	assert.Equal(t, "café.txt", pkzip.Name(&zip.File{FileHeader: zip.FileHeader{Name: "café.txt"}}))
+}
@@ -0,0 +1,208 @@
+package helper
+
+// Package file preview.go contains the extractor of a web-ready preview of a text file.
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/Defacto2/helper/ansi"
+	"github.com/Defacto2/helper/sauce"
+	"golang.org/x/text/encoding"
+)
+
+const (
+	PreviewLines   = 50 // PreviewLines is the default maximum number of lines of a preview.
+	PreviewColumns = 80 // PreviewColumns is the default maximum number of columns of a preview line.
+	TabWidth       = 8  // TabWidth is the default number of columns between the tab stops.
+)
+
+// PreviewOptions are the options for a Preview.
+type PreviewOptions struct {
+	// Encoding forces the encoding of the text, otherwise the encoding is detected.
+	Encoding encoding.Encoding
+	// Lines is the maximum number of lines, or PreviewLines when zero.
+	Lines int
+	// Columns is the maximum number of columns of each line, or PreviewColumns when zero.
+	Columns int
+	// TabWidth is the number of columns between the tab stops, or TabWidth when zero.
+	TabWidth int
+	// Glyphs decodes the CP-437 control characters as their pictographs, see UTF8Options.
+	Glyphs bool
+}
+
+// TextPreview is the preview of a text file.
+type TextPreview struct {
+	// Text is the UTF-8 encoded preview using LF line endings,
+	// without any ANSI escape sequences, control characters or trailing whitespace.
+	Text string
+	// Encoding is the detected or forced encoding of the original text.
+	Encoding encoding.Encoding
+	// Lines is the number of lines of the original text.
+	Lines int
+	// Truncated is true when the preview is missing lines or columns of the original text.
+	Truncated bool
+}
+
+// HTML returns the preview text escaped for use in an HTML element, such as a pre element.
+func (p TextPreview) HTML() string {
+	return html.EscapeString(p.Text)
+}
+
+// Preview returns the first lines of the text of the reader, decoded to UTF-8 and safe for HTML.
+// The whole text is read into memory, so the SAUCE metadata can be removed and the lines counted.
+//
+// The encoding is detected unless it is set by the options, the line endings are normalized to LF,
+// the tabs are expanded to spaces and the ANSI escape sequences and control characters are removed.
+// The preview is then truncated to the maximum number of lines and columns of the options.
+func Preview(r io.Reader, opts PreviewOptions) (TextPreview, error) {
+	if r == nil {
+		return TextPreview{}, ErrReader
+	}
+	p, err := io.ReadAll(r)
+	if err != nil {
+		return TextPreview{}, fmt.Errorf("preview %w", err)
+	}
+	p = sauce.Trim(p)
+	e := opts.Encoding
+	if e == nil {
+		// the escape sequences are evidence of the encoding, such as the Amiga CSI
+		d := NewDetector(0)
+		_, _ = d.Write(p)
+		e = d.Encoding()
+	}
+	ascii := asciiCompatible(e)
+	if ascii {
+		p = ansi.Strip(p)
+	}
+	ur, err := NewUTF8Reader(bytes.NewReader(p), UTF8Options{
+		Encoding: e,
+		Newlines: true,
+		Glyphs:   opts.Glyphs,
+	})
+	if err != nil {
+		return TextPreview{}, fmt.Errorf("preview %w", err)
+	}
+	var rr io.RuneReader = bufio.NewReader(ur)
+	if !ascii {
+		// the wide Unicode encodings are decoded before the escape sequences are removed
+		b, err := io.ReadAll(ur)
+		if err != nil {
+			return TextPreview{}, fmt.Errorf("preview %w", err)
+		}
+		rr = bytes.NewReader(ansi.Strip(b))
+	}
+	pv := previewer{
+		lines:   defaultInt(opts.Lines, PreviewLines),
+		columns: defaultInt(opts.Columns, PreviewColumns),
+		tab:     defaultInt(opts.TabWidth, TabWidth),
+	}
+	if err := pv.read(rr); err != nil {
+		return TextPreview{}, fmt.Errorf("preview %w", err)
+	}
+	return TextPreview{
+		Text:      strings.Join(pv.text, "\n"),
+		Encoding:  ur.Encoding(),
+		Lines:     pv.count,
+		Truncated: pv.truncated,
+	}, nil
+}
+
+// asciiCompatible returns true if the encoding uses the ASCII bytes for the ANSI escape sequences,
+// unlike the UTF-16 and UTF-32 encodings.
+func asciiCompatible(e encoding.Encoding) bool {
+	const sgr = "\x1b[0m"
+	p, err := e.NewEncoder().Bytes([]byte(sgr))
+	return err == nil && string(p) == sgr
+}
+
+// defaultInt returns i, or the default value when i is zero or less.
+func defaultInt(i, value int) int {
+	if i <= 0 {
+		return value
+	}
+	return i
+}
+
+// previewer is the state of the line and column truncation of a preview.
+type previewer struct {
+	lines, columns, tab int // lines, columns and tab are the options of the preview
+
+	text      []string        // text are the lines of the preview
+	line      strings.Builder // line is the current line of the preview
+	column    int             // column is the column of the current line
+	count     int             // count is the number of lines of the text
+	empty     bool            // empty is true when the current line has no runes
+	truncated bool            // truncated is true when the preview is missing lines or columns
+}
+
+// read reads the runes of the UTF-8 text, without its ANSI escape sequences,
+// and keeps the lines of the preview.
+func (pv *previewer) read(r io.RuneReader) error {
+	pv.empty = true
+	for {
+		char, _, err := r.ReadRune()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case char == '\n':
+			pv.newline()
+		case char == '\t':
+			pv.write(' ', tabStop(pv.tab, pv.column))
+		case unicode.IsControl(char):
+			continue
+		default:
+			pv.write(char, 1)
+		}
+	}
+	if !pv.empty {
+		pv.newline()
+	}
+	return nil
+}
+
+// tabStop returns the number of columns to the next tab stop from the column.
+func tabStop(width, column int) int {
+	return width - column%width
+}
+
+// write adds the rune n times to the current line, unless the line or the preview is full.
+func (pv *previewer) write(char rune, n int) {
+	pv.empty = false
+	if pv.count >= pv.lines {
+		return
+	}
+	for range n {
+		if pv.column >= pv.columns {
+			if char != ' ' {
+				pv.truncated = true
+			}
+			return
+		}
+		pv.line.WriteRune(char)
+		pv.column++
+	}
+}
+
+// newline ends the current line and counts the lines of the text.
+func (pv *previewer) newline() {
+	if pv.count < pv.lines {
+		pv.text = append(pv.text, strings.TrimRightFunc(pv.line.String(), unicode.IsSpace))
+	} else {
+		pv.truncated = true
+	}
+	pv.count++
+	pv.line.Reset()
+	pv.column = 0
+	pv.empty = true
+}
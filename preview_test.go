@@ -0,0 +1,118 @@
+package helper_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/codepage"
+	"github.com/Defacto2/helper/sauce"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func ExamplePreview() {
+	const s = "\x1b[1;33mHello\tworld\x1b[0m\r\nline two\r\nline three\r\n"
+	p, _ := helper.Preview(strings.NewReader(s), helper.PreviewOptions{Lines: 2})
+	fmt.Println(p.Text)
+	fmt.Println(p.Lines, p.Truncated)
+	// Output:
+	// Hello   world
+	// line two
+	// 3 true
+}
+
+func TestPreview(t *testing.T) {
+	t.Parallel()
+	_, err := helper.Preview(nil, helper.PreviewOptions{})
+	require.ErrorIs(t, err, helper.ErrReader)
+
+	f, err := os.Open("testdata/INFINITY.NFO")
+	require.NoError(t, err)
+	defer f.Close()
+	p, err := helper.Preview(f, helper.PreviewOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, charmap.CodePage437, p.Encoding)
+	assert.Equal(t, 21, p.Lines)
+	assert.True(t, p.Truncated, "the logo is wider than 80 columns")
+	assert.Contains(t, p.Text, "Release  : Café Racer Deluxe")
+	assert.NotContains(t, p.Text, "\r")
+	lines := strings.Split(p.Text, "\n")
+	assert.Len(t, lines, 21)
+	assert.Empty(t, lines[0])
+	assert.Equal(t, "     ∙ Unzip to your hard drive and type CAFE to begin.", lines[16])
+
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	p, err = helper.Preview(f, helper.PreviewOptions{Columns: 100})
+	require.NoError(t, err)
+	assert.False(t, p.Truncated)
+}
+
+func TestPreviewTruncate(t *testing.T) {
+	t.Parallel()
+	s := strings.Repeat("0123456789", 10) + "\nshort\n\tx\n"
+	p, err := helper.Preview(strings.NewReader(s), helper.PreviewOptions{Columns: 20, TabWidth: 4})
+	require.NoError(t, err)
+	assert.Equal(t, "01234567890123456789\nshort\n    x", p.Text)
+	assert.Equal(t, 3, p.Lines)
+	assert.True(t, p.Truncated)
+
+	// trailing spaces beyond the columns are not a truncation
+	p, err = helper.Preview(strings.NewReader("abc      \nno newline"), helper.PreviewOptions{Columns: 4})
+	require.NoError(t, err)
+	assert.Equal(t, "abc\nno n", p.Text)
+	assert.Equal(t, 2, p.Lines)
+	assert.True(t, p.Truncated)
+
+	p, err = helper.Preview(strings.NewReader("abc      \n"), helper.PreviewOptions{Columns: 4})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", p.Text)
+	assert.False(t, p.Truncated)
+
+	p, err = helper.Preview(strings.NewReader(""), helper.PreviewOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, p.Text)
+	assert.Zero(t, p.Lines)
+}
+
+func TestPreviewSanitize(t *testing.T) {
+	t.Parallel()
+	art := []byte("\x1b[2J\x1b[0;1;31m<b>Hi</b>\x1b[0m \x07&\x00\x1bc\r\n\x1b[0m")
+	data, err := sauce.Append(art, sauce.Record{Title: "Art"})
+	require.NoError(t, err)
+	p, err := helper.Preview(strings.NewReader(string(data)), helper.PreviewOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "<b>Hi</b> &", p.Text)
+	assert.Equal(t, "&lt;b&gt;Hi&lt;/b&gt; &amp;", p.HTML())
+	assert.Equal(t, 1, p.Lines)
+
+	// the Amiga control sequence introducer
+	p, err = helper.Preview(strings.NewReader("\x9b1;33mAmiga\x9b0m\n"), helper.PreviewOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, codepage.Topaz, p.Encoding)
+	assert.Equal(t, "Amiga", p.Text)
+
+	// the cursor forward sequences of ANSI art keep the words apart
+	p, err = helper.Preview(strings.NewReader("\x1b[1;33mWelcome\x1b[3Cto\x1b[1Cthe\x1b[5CBBS\r\n"),
+		helper.PreviewOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome   to the     BBS", p.Text)
+
+	// a wide Unicode text is decoded before the sequences are removed
+	wide, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String("\x1b[1;33mHi\x1b[2Cthere\r\n")
+	require.NoError(t, err)
+	p, err = helper.Preview(strings.NewReader(wide), helper.PreviewOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Hi  there", p.Text)
+
+	p, err = helper.Preview(strings.NewReader("\x01 \x03\r\n"),
+		helper.PreviewOptions{Encoding: charmap.CodePage437, Glyphs: true})
+	require.NoError(t, err)
+	assert.Equal(t, "☺ ♥", p.Text)
+}
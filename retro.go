@@ -0,0 +1,163 @@
+package helper
+
+// Package file retro.go contains the detection of the Commodore PETSCII and Atari ATASCII texts.
+
+import (
+	"bytes"
+
+	"github.com/Defacto2/helper/codepage"
+	"golang.org/x/text/encoding"
+)
+
+const (
+	RulePETSCII Rule = "petscii character"   // a Commodore color, cursor or case control code, or a PETSCII capital
+	RuleATASCII Rule = "atascii end of line" // an Atari end of line character, 0x9b, that is not an Amiga CSI
+)
+
+// retro returns the PETSCII or ATASCII encoding of the text, or nil if it is neither.
+//
+// Neither home computer uses the LF line ending, so any text with a LF is ignored.
+// An ATASCII text uses at least two 0x9b end of line characters and no CR line endings.
+// A PETSCII text uses the CR line ending, rarely uses the ASCII lower case letters,
+// never uses ANSI escape sequences, and uses the Commodore control codes or the PETSCII
+// capital letters 0xc1 to 0xda. So a single line of a name, a DIZ line or a comment
+// that uses the letters and currency symbols of an MS-DOS code page is never a home computer text.
+func (x *examination) retro(p []byte) encoding.Encoding {
+	var lf, cr, eols, upper, lower int
+	for i, char := range p {
+		switch {
+		case char == '\n':
+			lf++
+		case char == '\r':
+			cr++
+		case eol(p, i):
+			eols++
+		case char >= 'A' && char <= 'Z':
+			upper++
+		case char >= 'a' && char <= 'z':
+			lower++
+		}
+	}
+	if lf > 0 {
+		return nil
+	}
+	const minimum = 2
+	if cr == 0 && eols >= minimum {
+		for i := range p {
+			if eol(p, i) {
+				x.add(RuleATASCII, i, p[i:i+1], codepage.ATASCII)
+			}
+		}
+		return codepage.ATASCII
+	}
+	// a PETSCII text can use the ASCII lower case letters for the shifted capitals,
+	// but these are rare
+	const ratio = 20
+	if cr == 0 || upper == 0 || lower*ratio > upper || bytes.Contains(p, []byte("\x1b[")) {
+		return nil
+	}
+	return x.petscii(p)
+}
+
+// petscii returns the PETSCII character set of the text, or nil if there are too few PETSCII characters.
+// The high Commodore control codes 0x9c to 0x9f are also symbols of the MS-DOS code pages,
+// so the text must also use a case switch, a shifted capital letter or a low control code.
+func (x *examination) petscii(p []byte) encoding.Encoding {
+	const (
+		lowercase = 0x0e // switch to the lower and upper case character set
+		uppercase = 0x8e // switch to the upper case and graphics character set
+		capitalA  = 0xc1 // A in the lower and upper case character set
+		capitalZ  = 0xda // Z in the lower and upper case character set
+		minimum   = 2    // minimum number of matches
+	)
+	const high = 0x80 // the start of the high control codes
+	shifted, strong := 0, 0
+	start := len(x.evidence)
+	for i, char := range p {
+		switch {
+		case char == lowercase:
+			shifted++
+			strong++
+			x.add(RulePETSCII, i, p[i:i+1], codepage.PETSCIIShifted)
+		case char == uppercase:
+			shifted--
+			strong++
+			x.add(RulePETSCII, i, p[i:i+1], codepage.PETSCII)
+		case commodore(char):
+			if char < high {
+				strong++
+			}
+			x.add(RulePETSCII, i, p[i:i+1], codepage.PETSCII)
+		case char >= capitalA && char <= capitalZ:
+			// a capital letter followed by a lower case letter, 0x41 to 0x5a, of the shifted set
+			if _, next := neighbours(p, i); next >= 'A' && next <= 'Z' {
+				shifted++
+				strong++
+				x.add(RulePETSCII, i, p[i:i+2], codepage.PETSCIIShifted)
+			}
+		}
+	}
+	if x.tally[RulePETSCII] < minimum || strong == 0 {
+		x.evidence = x.evidence[:start]
+		delete(x.tally, RulePETSCII)
+		return nil
+	}
+	if shifted > 0 {
+		return codepage.PETSCIIShifted
+	}
+	return codepage.PETSCII
+}
+
+// eol returns true if the character at the index of p is an ATASCII end of line character.
+// In other encodings 0x9b is a lower case letter within a word, such as the ø of CP-865,
+// a currency symbol that follows a number, such as the ¢ of CP-437, or an Amiga control sequence introducer.
+func eol(p []byte, i int) bool {
+	if p[i] != codepage.EOL || csi(p[i+1:]) {
+		return false
+	}
+	prev, next := neighbours(p, i)
+	lower := func(char byte) bool { return char >= 'a' && char <= 'z' }
+	if lower(prev) && lower(next) {
+		return false
+	}
+	if i == len(p)-1 {
+		return prev < '0' || prev > '9'
+	}
+	// a new line never starts with a space or punctuation
+	switch next {
+	case ' ', '.', ',', ';', ':', '!', '?', ')':
+		return false
+	}
+	return true
+}
+
+// commodore returns true if the character is a Commodore color, reverse video or cursor control code
+// that is rarely found in other texts. The codes 0x80 to 0x9a are ignored,
+// as they are the accented letters of CP-437 and the other MS-DOS code pages.
+func commodore(char byte) bool {
+	switch char {
+	case 0x05, 0x11, 0x12, 0x13, 0x1c, 0x1d, 0x1e, 0x1f, // white, down, reverse, home, red, right, green, blue
+		0x9c, 0x9d, 0x9e, 0x9f: // purple, left, yellow, cyan
+		return true
+	}
+	return false
+}
+
+// csi returns true if p begins with the parameters and final byte of an Amiga control sequence,
+// such as "0;33m" following a 0x9b control sequence introducer.
+func csi(p []byte) bool {
+	const maxParams = 16
+	for i, char := range p {
+		switch {
+		case i > maxParams:
+			return false
+		case (char >= '0' && char <= '9') || char == ';':
+			continue
+		case char >= '@' && char <= '~':
+			return i > 0 || char == 'm' || char == 'H' || char == 'J' || char == 'K'
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package helper_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/codepage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+)
+
+func TestDetectRetro(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		text   string
+		expect encoding.Encoding
+	}{
+		{"atascii", "READY\x9bLOAD \"D:GAME\"\x9bRUN\x9b", codepage.ATASCII},
+		{"atascii end", "HELLO ATARI\x9bREADY\x9b", codepage.ATASCII},
+		{"petscii shifted", "\x0e\xc8ELLO \xd7ORLD\r\xc7REETINGS TO ALL\r", codepage.PETSCIIShifted},
+		{"petscii controls", "\x93\x05HELLO WORLD\r\x1cRED TEXT\r", codepage.PETSCII},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d, err := helper.Detect(strings.NewReader(tt.text))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, d.Encoding(), "wanted %s but got %s", tt.expect, d.Encoding())
+		})
+	}
+}
+
+func TestDetectNotRetro(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"amiga csi", "\x9b1;33mHello\x9b0m world\x9b2J"},
+		{"ascii", "HELLO WORLD"},
+		{"unix", "READY\x9bLOAD\x9b\n"},
+		{"nordic", "S\x9bren og \x9dystein p\x86 Bj\x9brn\x9bya"},
+		{"ansi", "\x1b[1;33mHELLO \x93\x05WORLD\x1b[0m\r"},
+		{"single atascii line", "HELLO ATARI\x9b"},
+		{"cp-437 german", "M\x9aNCHEN \x9aBER ALLES"},
+		{"cp-437 german line", "M\x9aNCHEN \x9aBER ALLES\r"},
+		{"cp-865 name", "S\x9dREN \x9dSTERGAARD"},
+		{"cp-865 name line", "S\x9dREN \x9dSTERGAARD\r"},
+		{"cp-437 cents", "Only 5\x9b or 10\x9b each"},
+		{"cp-437 cents end", "ONLY 5\x9b OR 10\x9b"},
+		{"cp-437 accents", "\x90COLE \x80A\x99A \x8eRGER\r"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d, err := helper.Detect(strings.NewReader(tt.text))
+			require.NoError(t, err)
+			for _, e := range []encoding.Encoding{codepage.ATASCII, codepage.PETSCII, codepage.PETSCIIShifted} {
+				assert.NotEqual(t, e, d.Encoding(), "got %s", d.Encoding())
+			}
+			assert.Zero(t, d.Tally[helper.RuleATASCII]+d.Tally[helper.RulePETSCII])
+		})
+	}
+}
+
+func TestDetectRetroEvidence(t *testing.T) {
+	t.Parallel()
+	d, err := helper.Detect(strings.NewReader("READY\x9bRUN\x9b"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, d.Tally[helper.RuleATASCII])
+	assert.Equal(t, 2, d.Candidates[0].Score)
+
+	d, err = helper.Detect(strings.NewReader("\x0e\xc8ELLO \xd7ORLD\r"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, d.Tally[helper.RulePETSCII])
+	assert.Zero(t, d.Tally[helper.RuleATASCII])
+}
+
+func TestNewUTF8ReaderPETSCII(t *testing.T) {
+	t.Parallel()
+	p := []byte("\x0e\xc8ELLO \xd7ORLD\r\xc7REETINGS\r")
+	r, err := helper.NewUTF8Reader(bytes.NewReader(p), helper.UTF8Options{Newlines: true})
+	require.NoError(t, err)
+	assert.Equal(t, codepage.PETSCIIShifted, r.Encoding())
+	p, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "\x0eHello World\nGreetings\n", string(p))
+}
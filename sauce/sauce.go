@@ -0,0 +1,408 @@
+// Package sauce reads and writes the SAUCE metadata records of text art and other files.
+//
+// The Standard Architecture for Universal Comment Extensions (SAUCE) is a 128 byte record
+// that is appended to the end of a file, optionally together with a block of comment lines
+// and a preceding MS-DOS end-of-file marker, 0x1a.
+// The specification is at [SAUCE].
+//
+// [SAUCE]: https://www.acid.org/info/sauce/sauce.htm
+package sauce
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	// Size is the length in bytes of a SAUCE record.
+	Size = 128
+	// ID is the identifier at the start of a SAUCE record.
+	ID = "SAUCE"
+	// Version is the only published version of the SAUCE record.
+	Version = "00"
+	// CommentID is the identifier at the start of a SAUCE comment block.
+	CommentID = "COMNT"
+	// CommentSize is the length in bytes of each line in a comment block.
+	CommentSize = 64
+	// MaxComments is the maximum number of lines in a comment block.
+	MaxComments = 255
+	// EOF is the MS-DOS end-of-file marker that precedes the comment block and record.
+	EOF = 0x1a
+	// DateLayout is the layout of the date field, CCYYMMDD.
+	DateLayout = "20060102"
+)
+
+var (
+	ErrComment  = errors.New("sauce comment block is missing or incomplete")
+	ErrDataType = errors.New("sauce data type is unknown")
+	ErrDate     = errors.New("sauce date is invalid")
+	ErrLength   = errors.New("sauce field is too long")
+	ErrNoRecord = errors.New("sauce record not found")
+	ErrVersion  = errors.New("sauce version is unsupported")
+)
+
+// DataType is the type of data that is described by a record.
+type DataType uint8
+
+const (
+	None       DataType = iota // None is undefined data.
+	Character                  // Character is a text based file, such as ASCII and ANSI text.
+	Bitmap                     // Bitmap is a raster graphic image or animation.
+	Vector                     // Vector is a vector graphic image.
+	Audio                      // Audio is a sound file.
+	BinaryText                 // BinaryText is a raw memory copy of a text mode screen, a .BIN file.
+	XBin                       // XBin is an extended binary text file.
+	Archive                    // Archive is a compressed archive file.
+	Executable                 // Executable is a program file.
+)
+
+// String returns the name of the data type.
+func (d DataType) String() string {
+	names := [...]string{
+		"None", "Character", "Bitmap", "Vector", "Audio",
+		"BinaryText", "XBin", "Archive", "Executable",
+	}
+	if int(d) >= len(names) {
+		return fmt.Sprintf("DataType(%d)", d)
+	}
+	return names[d]
+}
+
+// The file types of the Character data type.
+const (
+	ASCII      uint8 = iota // ASCII is plain text.
+	ANSI                    // ANSI is text with ANSI escape sequences.
+	ANSIMation              // ANSIMation is an animated ANSI text.
+	RIPScript               // RIPScript is a Remote Imaging Protocol script.
+	PCBoard                 // PCBoard is text with PCBoard color codes.
+	Avatar                  // Avatar is text with Avatar color codes.
+	HTML                    // HTML is a HyperText Markup Language file.
+	Source                  // Source is programming source code.
+	TundraDraw              // TundraDraw is a TundraDraw file with 24-bit colors.
+)
+
+// Flags are the ANSI display flags of the Character and BinaryText data types.
+type Flags uint8
+
+// The bits of the Flags.
+const (
+	NonBlink      Flags = 1 << iota // NonBlink is the iCE colors flag, that uses the blink bit for bright backgrounds.
+	Spacing8                        // Spacing8 selects an 8 pixel wide font.
+	Spacing9                        // Spacing9 selects a 9 pixel wide font.
+	AspectStretch                   // AspectStretch displays the text with the legacy, stretched aspect ratio.
+	AspectSquare                    // AspectSquare displays the text with square pixels.
+)
+
+// ICEColors returns true if the blink bit is used for bright background colors.
+func (f Flags) ICEColors() bool {
+	return f&NonBlink != 0
+}
+
+// Record is a SAUCE metadata record.
+type Record struct {
+	Title    string    // Title of the file, up to 35 characters.
+	Author   string    // Author is the nick, handle or name of the creator, up to 20 characters.
+	Group    string    // Group is the name of the group or company of the creator, up to 20 characters.
+	Date     time.Time // Date is the creation date.
+	FileSize uint32    // FileSize is the original size of the file, excluding the SAUCE data.
+	DataType DataType  // DataType is the type of data.
+	FileType uint8     // FileType is the type of file, which depends on the DataType.
+	TInfo1   uint16    // TInfo1 is type dependent, for Character files it is the width in columns.
+	TInfo2   uint16    // TInfo2 is type dependent, for Character files it is the number of lines.
+	TInfo3   uint16    // TInfo3 is type dependent.
+	TInfo4   uint16    // TInfo4 is type dependent.
+	Flags    Flags     // Flags are type dependent, for Character and BinaryText files they are the ANSI flags.
+	Font     string    // Font is the name of the font used to display the file, up to 22 characters.
+	Comments []string  // Comments are the lines of the comment block, each up to 64 characters.
+
+	// CommentLines is the number of comment lines that the record claims to have.
+	// It is set when reading a record and is otherwise ignored.
+	CommentLines int
+	// RawDate is the date field when it is not a valid CCYYMMDD date, such as "19940000",
+	// which leaves the Date as zero. It is set when reading a record and is otherwise ignored.
+	RawDate string
+}
+
+// Width returns the width in columns of the Character, BinaryText and XBin data types,
+// or 0 if it is unknown.
+func (r Record) Width() int {
+	switch r.DataType {
+	case Character, XBin:
+		return int(r.TInfo1)
+	case BinaryText:
+		const half = 2
+		return int(r.FileType) * half
+	}
+	return 0
+}
+
+// Rows returns the number of rows of the Character and XBin data types,
+// or 0 if it is unknown.
+func (r Record) Rows() int {
+	switch r.DataType {
+	case Character, XBin:
+		return int(r.TInfo2)
+	}
+	return 0
+}
+
+// ICEColors returns true if the record uses iCE colors.
+func (r Record) ICEColors() bool {
+	switch r.DataType {
+	case Character, BinaryText:
+		return r.Flags.ICEColors()
+	}
+	return false
+}
+
+// Valid returns an error if the record is not valid.
+// For a record that has been read, it also reports an invalid date field
+// and a comment block that is missing or has fewer lines than the CommentLines.
+func (r Record) Valid() error {
+	if r.DataType > Executable {
+		return fmt.Errorf("%w: %d", ErrDataType, r.DataType)
+	}
+	if r.RawDate != "" {
+		return fmt.Errorf("%w: %q", ErrDate, r.RawDate)
+	}
+	if r.CommentLines > 0 && r.CommentLines != len(r.Comments) {
+		return fmt.Errorf("%w: %d of %d lines", ErrComment, len(r.Comments), r.CommentLines)
+	}
+	return r.lengths()
+}
+
+// lengths returns an error if any of the text fields are too long.
+func (r Record) lengths() error {
+	const title, author, group, font = 35, 20, 20, 22
+	fields := []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"title", r.Title, title},
+		{"author", r.Author, author},
+		{"group", r.Group, group},
+		{"font", r.Font, font},
+	}
+	for _, f := range fields {
+		if n := utf8.RuneCountInString(f.value); n > f.max {
+			return fmt.Errorf("%w: %s is %d characters", ErrLength, f.name, n)
+		}
+	}
+	if len(r.Comments) > MaxComments {
+		return fmt.Errorf("%w: %d comment lines", ErrLength, len(r.Comments))
+	}
+	for i, c := range r.Comments {
+		if n := utf8.RuneCountInString(c); n > CommentSize {
+			return fmt.Errorf("%w: comment line %d is %d characters", ErrLength, i+1, n)
+		}
+	}
+	return nil
+}
+
+// Read reads and returns the SAUCE record and any comment block from the end of r,
+// where size is the length of r in bytes.
+func Read(r io.ReaderAt, size int64) (Record, error) {
+	if size < Size {
+		return Record{}, ErrNoRecord
+	}
+	p := make([]byte, Size)
+	if _, err := r.ReadAt(p, size-Size); err != nil {
+		return Record{}, fmt.Errorf("sauce read record %w", err)
+	}
+	rec, err := record(p)
+	if err != nil {
+		return Record{}, err
+	}
+	if rec.CommentLines == 0 {
+		return rec, nil
+	}
+	block := int64(len(CommentID) + rec.CommentLines*CommentSize)
+	offset := size - Size - block
+	if offset < 0 {
+		return rec, nil
+	}
+	c := make([]byte, block)
+	if _, err := r.ReadAt(c, offset); err != nil {
+		return Record{}, fmt.Errorf("sauce read comments %w", err)
+	}
+	if !bytes.HasPrefix(c, []byte(CommentID)) {
+		return rec, nil
+	}
+	c = c[len(CommentID):]
+	for i := range rec.CommentLines {
+		rec.Comments = append(rec.Comments, text(c[i*CommentSize:(i+1)*CommentSize]))
+	}
+	return rec, nil
+}
+
+// Decode returns the SAUCE record and any comment block at the end of p.
+func Decode(p []byte) (Record, error) {
+	return Read(bytes.NewReader(p), int64(len(p)))
+}
+
+// record parses the 128 byte SAUCE record.
+// An invalid date is kept in the RawDate, as the record still describes the file.
+func record(p []byte) (Record, error) {
+	if !bytes.HasPrefix(p, []byte(ID)) {
+		return Record{}, ErrNoRecord
+	}
+	if v := string(p[5:7]); v != Version {
+		return Record{}, fmt.Errorf("%w: %q", ErrVersion, v)
+	}
+	le := binary.LittleEndian
+	rec := Record{
+		Title:        text(p[7:42]),
+		Author:       text(p[42:62]),
+		Group:        text(p[62:82]),
+		FileSize:     le.Uint32(p[90:94]),
+		DataType:     DataType(p[94]),
+		FileType:     p[95],
+		TInfo1:       le.Uint16(p[96:98]),
+		TInfo2:       le.Uint16(p[98:100]),
+		TInfo3:       le.Uint16(p[100:102]),
+		TInfo4:       le.Uint16(p[102:104]),
+		CommentLines: int(p[104]),
+		Flags:        Flags(p[105]),
+		Font:         text(p[106:128]),
+	}
+	if d := strings.TrimSpace(string(p[82:90])); d != "" {
+		date, err := time.Parse(DateLayout, d)
+		if err != nil {
+			rec.RawDate = d
+			return rec, nil
+		}
+		rec.Date = date
+	}
+	return rec, nil
+}
+
+// text decodes the CP-437 field and removes the space or null padding.
+func text(p []byte) string {
+	p = bytes.TrimRight(p, " \x00")
+	s, err := charmap.CodePage437.NewDecoder().Bytes(p)
+	if err != nil {
+		return string(p)
+	}
+	return string(s)
+}
+
+// Index returns the index of the SAUCE trailer in p, which is the position of the end-of-file marker,
+// the comment block or the record. If there is no trailer, the length of p less any trailing
+// end-of-file markers is returned.
+func Index(p []byte) int {
+	return int(DataSize(bytes.NewReader(p), int64(len(p))))
+}
+
+// DataSize returns the length of the data in r without the SAUCE trailer,
+// where size is the length of r in bytes.
+// The trailer is the record, any comment block and any end-of-file markers that precede them.
+func DataSize(r io.ReaderAt, size int64) int64 {
+	end := size
+	if rec, err := Read(r, size); err == nil {
+		end -= Size
+		if len(rec.Comments) > 0 {
+			end -= int64(len(CommentID) + len(rec.Comments)*CommentSize)
+		}
+	}
+	// remove the end-of-file markers
+	b := make([]byte, 1)
+	for end > 0 {
+		if _, err := r.ReadAt(b, end-1); err != nil || b[0] != EOF {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+// Trim returns p without the SAUCE trailer and any end-of-file markers at the end.
+func Trim(p []byte) []byte {
+	return p[:Index(p)]
+}
+
+// MarshalBinary returns the comment block, if there are comments, and the 128 byte record.
+// The text fields are encoded as CP-437 and padded with spaces.
+func (r Record) MarshalBinary() ([]byte, error) {
+	if err := r.lengths(); err != nil {
+		return nil, err
+	}
+	if r.DataType > Executable {
+		return nil, fmt.Errorf("%w: %d", ErrDataType, r.DataType)
+	}
+	buf := new(bytes.Buffer)
+	if len(r.Comments) > 0 {
+		buf.WriteString(CommentID)
+		for _, c := range r.Comments {
+			if err := field(buf, c, CommentSize, ' '); err != nil {
+				return nil, err
+			}
+		}
+	}
+	buf.WriteString(ID + Version)
+	const title, author, group, font = 35, 20, 20, 22
+	for _, f := range []struct {
+		s string
+		n int
+	}{{r.Title, title}, {r.Author, author}, {r.Group, group}} {
+		if err := field(buf, f.s, f.n, ' '); err != nil {
+			return nil, err
+		}
+	}
+	date := strings.Repeat(" ", len(DateLayout))
+	if !r.Date.IsZero() {
+		date = r.Date.Format(DateLayout)
+	}
+	buf.WriteString(date)
+	le := binary.LittleEndian
+	buf.Write(le.AppendUint32(nil, r.FileSize))
+	buf.WriteByte(byte(r.DataType))
+	buf.WriteByte(r.FileType)
+	for _, v := range []uint16{r.TInfo1, r.TInfo2, r.TInfo3, r.TInfo4} {
+		buf.Write(le.AppendUint16(nil, v))
+	}
+	buf.WriteByte(byte(len(r.Comments)))
+	buf.WriteByte(byte(r.Flags))
+	if err := field(buf, r.Font, font, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// field writes the CP-437 encoded string padded to n bytes.
+func field(buf *bytes.Buffer, s string, n int, pad byte) error {
+	p, err := charmap.CodePage437.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		return fmt.Errorf("sauce encode %q: %w", s, err)
+	}
+	if len(p) > n {
+		return fmt.Errorf("%w: %q", ErrLength, s)
+	}
+	buf.Write(p)
+	buf.Write(bytes.Repeat([]byte{pad}, n-len(p)))
+	return nil
+}
+
+// Append returns the data of p with the record appended as a SAUCE trailer.
+// Any existing trailer in p is replaced and the FileSize of the record is set
+// to the length of the data.
+func Append(p []byte, r Record) ([]byte, error) {
+	data := Trim(p)
+	r.FileSize = uint32(len(data))
+	b, err := r.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, 0, len(data)+1+len(b))
+	dst = append(dst, data...)
+	dst = append(dst, EOF)
+	return append(dst, b...), nil
+}
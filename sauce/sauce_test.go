@@ -0,0 +1,142 @@
+package sauce_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Defacto2/helper/sauce"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func record() sauce.Record {
+	return sauce.Record{
+		Title:    "Café Racer",
+		Author:   "Jürgen",
+		Group:    "Infinity",
+		Date:     time.Date(1994, 4, 17, 0, 0, 0, 0, time.UTC),
+		DataType: sauce.Character,
+		FileType: sauce.ANSI,
+		TInfo1:   80,
+		TInfo2:   25,
+		Flags:    sauce.NonBlink | sauce.Spacing9,
+		Font:     "IBM VGA",
+		Comments: []string{"Greetings to all our friends", "in Zürich"},
+	}
+}
+
+func ExampleDecode() {
+	art := []byte("\x1b[1;33mHello world\x1b[0m\r\n")
+	p, _ := sauce.Append(art, sauce.Record{Title: "Hello", Author: "Ben", DataType: sauce.Character})
+	rec, _ := sauce.Decode(p)
+	fmt.Println(rec.Title, rec.Author, rec.FileSize)
+	// Output:
+	// Hello Ben 24
+}
+
+func TestAppendDecode(t *testing.T) {
+	t.Parallel()
+	data := []byte("Hello world\r\n")
+	p, err := sauce.Append(data, record())
+	require.NoError(t, err)
+	const trailer = 1 + 5 + 2*sauce.CommentSize + sauce.Size
+	assert.Len(t, p, len(data)+trailer)
+	assert.Equal(t, byte(sauce.EOF), p[len(data)])
+
+	rec, err := sauce.Decode(p)
+	require.NoError(t, err)
+	require.NoError(t, rec.Valid())
+	want := record()
+	want.FileSize = uint32(len(data))
+	want.CommentLines = 2
+	assert.Equal(t, want, rec)
+	assert.Equal(t, 80, rec.Width())
+	assert.Equal(t, 25, rec.Rows())
+	assert.True(t, rec.ICEColors())
+	assert.Equal(t, "Character", rec.DataType.String())
+
+	// replace the existing trailer
+	rec.Comments = nil
+	rec.Title = "Replaced"
+	p, err = sauce.Append(p, rec)
+	require.NoError(t, err)
+	assert.Len(t, p, len(data)+1+sauce.Size)
+	rec, err = sauce.Decode(p)
+	require.NoError(t, err)
+	assert.Equal(t, "Replaced", rec.Title)
+	assert.Empty(t, rec.Comments)
+}
+
+func TestTrim(t *testing.T) {
+	t.Parallel()
+	data := []byte("Hello world")
+	assert.Equal(t, data, sauce.Trim(data))
+	assert.Equal(t, data, sauce.Trim(append(bytes.Clone(data), sauce.EOF, sauce.EOF)))
+	p, err := sauce.Append(data, record())
+	require.NoError(t, err)
+	assert.Equal(t, data, sauce.Trim(p))
+	assert.Equal(t, len(data), sauce.Index(p))
+	assert.Equal(t, int64(len(data)), sauce.DataSize(bytes.NewReader(p), int64(len(p))))
+	assert.Empty(t, sauce.Trim(nil))
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+	_, err := sauce.Decode(nil)
+	require.ErrorIs(t, err, sauce.ErrNoRecord)
+	_, err = sauce.Decode(bytes.Repeat([]byte("x"), 200))
+	require.ErrorIs(t, err, sauce.ErrNoRecord)
+
+	p, err := sauce.Append([]byte("data"), record())
+	require.NoError(t, err)
+	bad := bytes.Clone(p)
+	copy(bad[len(bad)-sauce.Size+5:], "01")
+	_, err = sauce.Decode(bad)
+	require.ErrorIs(t, err, sauce.ErrVersion)
+
+	// an invalid date keeps the record and is reported by Valid
+	for _, date := range []string{"19941399", "19940000"} {
+		bad = bytes.Clone(p)
+		copy(bad[len(bad)-sauce.Size+82:], date)
+		rec, err := sauce.Decode(bad)
+		require.NoError(t, err, date)
+		assert.True(t, rec.Date.IsZero(), date)
+		assert.Equal(t, date, rec.RawDate)
+		assert.Equal(t, "Café Racer", rec.Title)
+		require.ErrorIs(t, rec.Valid(), sauce.ErrDate)
+		assert.Equal(t, []byte("data"), sauce.Trim(bad))
+	}
+
+	// a missing comment block is reported by Valid
+	bad = bytes.Clone(p)
+	copy(bad[5:], "XXXXX")
+	rec, err := sauce.Decode(bad)
+	require.NoError(t, err)
+	assert.Empty(t, rec.Comments)
+	require.ErrorIs(t, rec.Valid(), sauce.ErrComment)
+
+	// the CommentLines of a new record are ignored
+	require.NoError(t, sauce.Record{Comments: []string{"A comment"}}.Valid())
+}
+
+func TestMarshalBinary(t *testing.T) {
+	t.Parallel()
+	rec := sauce.Record{Title: "This title is much too long for a SAUCE record"}
+	_, err := rec.MarshalBinary()
+	require.ErrorIs(t, err, sauce.ErrLength)
+	rec = sauce.Record{DataType: 99}
+	_, err = rec.MarshalBinary()
+	require.ErrorIs(t, err, sauce.ErrDataType)
+	rec = sauce.Record{Title: "Snowman ☃"}
+	_, err = rec.MarshalBinary()
+	require.Error(t, err)
+
+	p, err := sauce.Record{Font: "IBM VGA"}.MarshalBinary()
+	require.NoError(t, err)
+	assert.Len(t, p, sauce.Size)
+	assert.Equal(t, "SAUCE00", string(p[:7]))
+	assert.Equal(t, "        ", string(p[82:90]))
+	assert.Equal(t, byte(0), p[sauce.Size-1])
+}
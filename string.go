@@ -15,6 +15,7 @@ import (
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/Defacto2/helper/sauce"
 	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
 	"golang.org/x/text/cases"
@@ -212,7 +213,9 @@ func FmtSlice(s string) string {
 }
 
 // MaxLineLength counts the character length of the longest line in a string.
+// Any SAUCE metadata and MS-DOS end-of-file markers at the end of the string are ignored.
 func MaxLineLength(s string) int {
+	s = string(sauce.Trim([]byte(s)))
 	lines := strings.Split(s, "\n")
 	max := 0
 	for _, line := range lines {
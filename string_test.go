@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/sauce"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -540,3 +541,10 @@ func TestTitleize(t *testing.T) {
 // 		})
 // 	}
 // }
+
+func TestMaxLineLengthSAUCE(t *testing.T) {
+	t.Parallel()
+	p, err := sauce.Append([]byte("a\nabcde\n"), sauce.Record{Title: "a title longer than the text"})
+	require.NoError(t, err)
+	assert.Equal(t, 5, helper.MaxLineLength(string(p)))
+}
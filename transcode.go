@@ -0,0 +1,76 @@
+package helper
+
+// Package file transcode.go contains the reader that decodes legacy text to UTF-8.
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/Defacto2/helper/codepage"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// UTF8Options are the options for a UTF8Reader.
+type UTF8Options struct {
+	// Encoding forces the encoding of the text, otherwise the encoding is detected.
+	Encoding encoding.Encoding
+	// Limit is the number of bytes sampled to detect the encoding, or SampleLimit when zero.
+	Limit int
+	// Newlines converts the CRLF and CR line endings to LF.
+	Newlines bool
+	// Glyphs decodes a CP-437 text using the codepage.CodePage437 glyph encoding,
+	// so the control characters are the pictographs seen on MS-DOS,
+	// except for the CR, LF, tab and escape controls that are kept.
+	Glyphs bool
+}
+
+// UTF8Reader is an io.Reader that decodes a text to UTF-8.
+type UTF8Reader struct {
+	reader   io.Reader
+	encoding encoding.Encoding
+}
+
+// NewUTF8Reader returns a reader that decodes the text of r to UTF-8.
+// Unless the encoding is set by the options, it is detected using
+// a sample of the text, which is read from r but not lost.
+func NewUTF8Reader(r io.Reader, opts UTF8Options) (*UTF8Reader, error) {
+	if r == nil {
+		return nil, ErrReader
+	}
+	e := opts.Encoding
+	if e == nil {
+		d := NewDetector(opts.Limit)
+		sample := new(bytes.Buffer)
+		w := io.MultiWriter(d, sample)
+		// read an extra byte so the detector knows if the text was truncated
+		if _, err := io.Copy(w, io.LimitReader(r, int64(d.limit)+1)); err != nil {
+			return nil, fmt.Errorf("new utf8 reader sample %w", err)
+		}
+		e = d.Encoding()
+		r = io.MultiReader(sample, r)
+	}
+	if opts.Glyphs && e == charmap.CodePage437 {
+		e = codepage.CodePage437(codepage.KeepControls)
+	}
+	var t transform.Transformer = e.NewDecoder()
+	if opts.Newlines {
+		t = transform.Chain(t, &newlines{eol: NewlineLF.Bytes()})
+	}
+	return &UTF8Reader{
+		reader:   transform.NewReader(r, t),
+		encoding: e,
+	}, nil
+}
+
+// Read reads the UTF-8 encoded text into p.
+func (u *UTF8Reader) Read(p []byte) (int, error) {
+	return u.reader.Read(p)
+}
+
+// Encoding returns the encoding that is used to decode the text.
+func (u *UTF8Reader) Encoding() encoding.Encoding {
+	return u.encoding
+}
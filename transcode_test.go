@@ -0,0 +1,96 @@
+package helper_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/codepage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestNewUTF8Reader(t *testing.T) {
+	t.Parallel()
+	_, err := helper.NewUTF8Reader(nil, helper.UTF8Options{})
+	require.ErrorIs(t, err, helper.ErrReader)
+
+	f, err := os.Open("testdata/INFINITY.NFO")
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := helper.NewUTF8Reader(f, helper.UTF8Options{Newlines: true})
+	require.NoError(t, err)
+	assert.Equal(t, charmap.CodePage437, r.Encoding())
+	p, err := io.ReadAll(r)
+	require.NoError(t, err)
+	s := string(p)
+	assert.Contains(t, s, "Release  : Café Racer Deluxe")
+	assert.Contains(t, s, "░▒▓█───")
+	assert.NotContains(t, s, "\r")
+
+	f, err = os.Open("testdata/WIN1252.TXT")
+	require.NoError(t, err)
+	defer f.Close()
+	r, err = helper.NewUTF8Reader(f, helper.UTF8Options{Limit: 100})
+	require.NoError(t, err)
+	assert.Equal(t, charmap.Windows1252, r.Encoding())
+	p, err = io.ReadAll(r)
+	require.NoError(t, err)
+	s = string(p)
+	assert.Contains(t, s, "the group’s “Retro Café” pack… it’s finally here!")
+	assert.Contains(t, s, "\r\n")
+}
+
+func TestNewUTF8ReaderForced(t *testing.T) {
+	t.Parallel()
+	r, err := helper.NewUTF8Reader(strings.NewReader("caf\xe9"),
+		helper.UTF8Options{Encoding: charmap.ISO8859_1})
+	require.NoError(t, err)
+	p, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "café", string(p))
+
+	p, err = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte("one\r\ntwo\rthree\n"))
+	require.NoError(t, err)
+	r, err = helper.NewUTF8Reader(bytes.NewReader(p), helper.UTF8Options{Newlines: true})
+	require.NoError(t, err)
+	assert.Equal(t, unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), r.Encoding())
+	p, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\nthree\n", string(p))
+}
+
+func TestNewUTF8ReaderNewlines(t *testing.T) {
+	t.Parallel()
+	// a large text ensures the CRLF line endings are split between reads
+	s := strings.Repeat("abc\r\n\r\rdef\n", 5000)
+	r, err := helper.NewUTF8Reader(iotest.OneByteReader(strings.NewReader(s)), helper.UTF8Options{Newlines: true})
+	require.NoError(t, err)
+	p, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("abc\n\n\ndef\n", 5000), string(p))
+}
+
+func TestNewUTF8ReaderGlyphs(t *testing.T) {
+	t.Parallel()
+	const s = "\x1b[31m\x03 Love \x0e\x7f\t\xdb\r\n"
+	r, err := helper.NewUTF8Reader(strings.NewReader(s),
+		helper.UTF8Options{Encoding: charmap.CodePage437, Glyphs: true})
+	require.NoError(t, err)
+	assert.Equal(t, codepage.CodePage437(codepage.KeepControls), r.Encoding())
+	p, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "\x1b[31m♥ Love ♫⌂\t█\r\n", string(p))
+
+	// the glyphs option only applies to CP-437 texts
+	r, err = helper.NewUTF8Reader(strings.NewReader("caf\xe9"),
+		helper.UTF8Options{Encoding: charmap.ISO8859_1, Glyphs: true})
+	require.NoError(t, err)
+	assert.Equal(t, charmap.ISO8859_1, r.Encoding())
+}
@@ -0,0 +1,95 @@
+package helper
+
+// Package file utf8.go contains the streaming validator of UTF-8 encoded texts.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// UTF8Sample is the number of bytes that UTF8 reads to validate a file.
+const UTF8Sample = 512
+
+// Validation is the result of a UTF-8 validation.
+// The counts stop at the first invalid sequence.
+type Validation struct {
+	Valid     bool  // Valid is true if the text is valid UTF-8 encoded text.
+	Invalid   int64 // Invalid is the offset of the first invalid sequence, or -1 when valid.
+	ASCII     int64 // ASCII is the number of single byte runes.
+	Multibyte int64 // Multibyte is the number of multibyte runes.
+	Size      int64 // Size is the number of bytes that were validated before any invalid sequence.
+	// Truncated is true if the text is longer than the sample,
+	// in which case an incomplete rune at the end of the sample is ignored.
+	Truncated bool
+}
+
+// ValidateUTF8 reads and validates the UTF-8 encoding of the text of the reader.
+// The sample is the maximum number of bytes to validate, or a full scan when zero or less.
+// Unlike utf8.Valid, a multibyte rune that is split by the end of the sample is not invalid.
+func ValidateUTF8(r io.Reader, sample int64) (Validation, error) {
+	if r == nil {
+		return Validation{}, ErrReader
+	}
+	if sample > 0 {
+		// read an extra byte to know if the text is truncated
+		r = io.LimitReader(r, sample+1)
+	}
+	const size = 32 * 1024
+	v := Validation{Invalid: -1}
+	buf := make([]byte, size)
+	carry := 0 // carry is the length of an incomplete rune at the start of buf
+	for {
+		n, err := r.Read(buf[carry:])
+		n += carry
+		if sample > 0 && v.Size+int64(n) > sample {
+			n = int(sample - v.Size)
+			v.Truncated = true
+		}
+		carry = v.validate(buf[:n])
+		if v.Invalid >= 0 {
+			v.Size = v.Invalid
+			return v, nil
+		}
+		v.Size += int64(n - carry)
+		copy(buf, buf[n-carry:n])
+		if v.Truncated || errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Validation{}, fmt.Errorf("validate utf8 %w", err)
+		}
+	}
+	if carry > 0 && !v.Truncated {
+		// the text ends with an incomplete rune
+		v.Invalid = v.Size
+		return v, nil
+	}
+	v.Size += int64(carry)
+	v.Valid = true
+	return v, nil
+}
+
+// validate counts the runes of p and returns the length of an incomplete rune at the end of p.
+// The offset of any invalid sequence is set using the size of the previously validated text.
+func (v *Validation) validate(p []byte) int {
+	for i := 0; i < len(p); {
+		if p[i] < utf8.RuneSelf {
+			v.ASCII++
+			i++
+			continue
+		}
+		if !utf8.FullRune(p[i:]) {
+			return len(p) - i
+		}
+		r, size := utf8.DecodeRune(p[i:])
+		if r == utf8.RuneError && size == 1 {
+			v.Invalid = v.Size + int64(i)
+			return 0
+		}
+		v.Multibyte++
+		i += size
+	}
+	return 0
+}
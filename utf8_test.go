@@ -0,0 +1,88 @@
+package helper_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Defacto2/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateUTF8(t *testing.T) {
+	t.Parallel()
+	_, err := helper.ValidateUTF8(nil, 0)
+	require.ErrorIs(t, err, helper.ErrReader)
+
+	tests := []struct {
+		name   string
+		text   string
+		sample int64
+		expect helper.Validation
+	}{
+		{"empty", "", 0, helper.Validation{Valid: true, Invalid: -1}},
+		{"ascii", "Hello", 0, helper.Validation{Valid: true, Invalid: -1, ASCII: 5, Size: 5}},
+		{"multibyte", "Café ☕", 0, helper.Validation{Valid: true, Invalid: -1, ASCII: 4, Multibyte: 2, Size: 9}},
+		{"invalid", "caf\xe9 ok", 0, helper.Validation{Invalid: 3, ASCII: 3, Size: 3}},
+		{"truncated eof", "café\xe2\x98", 0, helper.Validation{Invalid: 5, ASCII: 3, Multibyte: 1, Size: 5}},
+		{"sample", "café☕ and more", 6, helper.Validation{
+			Valid: true, Invalid: -1, ASCII: 3, Multibyte: 1, Size: 6, Truncated: true,
+		}},
+		{"sample fits", "café", 5, helper.Validation{Valid: true, Invalid: -1, ASCII: 3, Multibyte: 1, Size: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// a one byte reader splits every multibyte rune between reads
+			v, err := helper.ValidateUTF8(iotest.OneByteReader(strings.NewReader(tt.text)), tt.sample)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, v)
+		})
+	}
+
+	_, err = helper.ValidateUTF8(iotest.ErrReader(io.ErrUnexpectedEOF), 0)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestValidateUTF8Large(t *testing.T) {
+	t.Parallel()
+	s := strings.Repeat("Grüße ☃ ", 20000)
+	v, err := helper.ValidateUTF8(strings.NewReader(s), 0)
+	require.NoError(t, err)
+	assert.True(t, v.Valid)
+	assert.Equal(t, int64(len(s)), v.Size)
+	assert.Equal(t, int64(20000*3), v.Multibyte)
+	assert.Equal(t, int64(20000*5), v.ASCII)
+
+	v, err = helper.ValidateUTF8(strings.NewReader(s+"\xff"), 0)
+	require.NoError(t, err)
+	assert.False(t, v.Valid)
+	assert.Equal(t, int64(len(s)), v.Invalid)
+}
+
+func TestUTF8Boundary(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	// the snowman is split by the end of the sample
+	text := strings.Repeat("a", helper.UTF8Sample-1) + "☃ and more"
+	name := filepath.Join(dir, "boundary.txt")
+	require.NoError(t, os.WriteFile(name, []byte(text), helper.WriteWriteRead))
+	ok, err := helper.UTF8(name)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	name = filepath.Join(dir, "latin1.txt")
+	require.NoError(t, os.WriteFile(name, []byte("caf\xe9"), helper.WriteWriteRead))
+	ok, err = helper.UTF8(name)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	name = filepath.Join(dir, "empty.txt")
+	require.NoError(t, os.WriteFile(name, nil, helper.WriteWriteRead))
+	_, err = helper.UTF8(name)
+	require.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,179 @@
+package helper
+
+// Package file zip.go contains the listing of ZIP archives with the CP-437 names and comments decoded.
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Defacto2/helper/codepage"
+	"github.com/Defacto2/helper/pkzip"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// ZipOptions are the options for a ZIP archive listing.
+type ZipOptions struct {
+	// Encoding forces the encoding of the names and comments that are not UTF-8,
+	// otherwise the encoding is detected.
+	Encoding encoding.Encoding
+}
+
+// ZipEntry is a file or directory of a ZIP archive.
+type ZipEntry struct {
+	Name             string       // Name is the path of the entry decoded to UTF-8.
+	Comment          string       // Comment is the entry comment decoded to UTF-8.
+	Method           pkzip.Method // Method is the compression method.
+	Modified         time.Time    // Modified is the modification time, which has no time zone for MS-DOS archives.
+	CompressedSize   int64        // CompressedSize is the size of the compressed data in bytes.
+	UncompressedSize int64        // UncompressedSize is the size of the file in bytes.
+	CRC32            uint32       // CRC32 is the checksum of the uncompressed data.
+	Dir              bool         // Dir is true when the entry is a directory.
+	Encrypted        bool         // Encrypted is true when the entry is password protected.
+}
+
+// ZipListing is the listing of a ZIP archive with the names and comments decoded to UTF-8.
+type ZipListing struct {
+	Entries []ZipEntry // Entries are the files and directories in the order of the central directory.
+	// Comment is the archive comment decoded to UTF-8, which keeps any ANSI escape sequences.
+	Comment string
+	// ANSI is the archive comment encoded as CP-437 for the renderers of the ansi package,
+	// which is the original comment when it uses CP-437.
+	ANSI []byte
+	// Encoding is the detected or forced encoding of the names and comments that are not UTF-8.
+	Encoding encoding.Encoding
+}
+
+// ListZipFile returns the listing of the named ZIP archive, see ListZip.
+func ListZipFile(name string, opts ZipOptions) (ZipListing, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return ZipListing{}, fmt.Errorf("list zip file os.open %w", err)
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return ZipListing{}, fmt.Errorf("list zip file file.stat %w", err)
+	}
+	return ListZip(f, st.Size(), opts)
+}
+
+// ListZip returns the listing of the ZIP archive of the reader with the given size.
+//
+// The archive/zip package returns the names and comments as raw bytes, while the archives
+// of the BBS era use the CP-437 encoding without marking it, and their comments are often ANSI adverts.
+// Unless the encoding is forced by the options, the names and comments that are not marked as UTF-8
+// are examined together using Detect. CP-437 is the default encoding of the ZIP specification,
+// so it is used unless there is evidence of UTF-8, Windows-1252 typography or a national MS-DOS code page.
+// The ISO-8859-1 result of Detect is never used, as it is only the fallback for a text without evidence.
+func ListZip(r io.ReaderAt, size int64, opts ZipOptions) (ZipListing, error) {
+	if r == nil {
+		return ZipListing{}, ErrReader
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return ZipListing{}, fmt.Errorf("list zip %w", err)
+	}
+	e := opts.Encoding
+	if e == nil {
+		e = zipEncoding(zr)
+	}
+	l := ZipListing{
+		Entries:  make([]ZipEntry, 0, len(zr.File)),
+		Encoding: e,
+	}
+	if l.Comment, err = decodeZip(e, zr.Comment); err != nil {
+		return ZipListing{}, fmt.Errorf("list zip comment %w", err)
+	}
+	if l.ANSI, err = zipANSI(e, zr.Comment, l.Comment); err != nil {
+		return ZipListing{}, fmt.Errorf("list zip comment %w", err)
+	}
+	const encrypted = 1 << 0
+	for _, f := range zr.File {
+		entry := ZipEntry{
+			Name:             f.Name,
+			Comment:          f.Comment,
+			Method:           pkzip.Method(f.Method),
+			Modified:         f.Modified,
+			CompressedSize:   int64(f.CompressedSize64),
+			UncompressedSize: int64(f.UncompressedSize64),
+			CRC32:            f.CRC32,
+			Dir:              f.FileInfo().IsDir(),
+			Encrypted:        f.Flags&encrypted != 0,
+		}
+		if f.NonUTF8 {
+			if entry.Name, err = decodeZip(e, f.Name); err != nil {
+				return ZipListing{}, fmt.Errorf("list zip name %w", err)
+			}
+			if entry.Comment, err = decodeZip(e, f.Comment); err != nil {
+				return ZipListing{}, fmt.Errorf("list zip entry comment %w", err)
+			}
+		}
+		l.Entries = append(l.Entries, entry)
+	}
+	return l, nil
+}
+
+// zipEncoding returns the encoding of the names and comments of the archive that are not UTF-8.
+func zipEncoding(zr *zip.Reader) encoding.Encoding {
+	var b bytes.Buffer
+	b.WriteString(zr.Comment)
+	for _, f := range zr.File {
+		if !f.NonUTF8 {
+			continue
+		}
+		b.WriteByte('\n')
+		b.WriteString(f.Name)
+		if f.Comment != "" {
+			b.WriteByte('\n')
+			b.WriteString(f.Comment)
+		}
+	}
+	return zipEvidence(b.Bytes())
+}
+
+// zipEvidence returns the detected encoding of the names or comments when there is evidence for it,
+// otherwise CP-437. A national MS-DOS code page also needs more than one letter that is
+// only plausible in that code page, as a single letter is weak evidence in a short name.
+func zipEvidence(p []byte) encoding.Encoding {
+	const minimum = 2
+	d := detect(p)
+	e := d.Encoding()
+	switch e {
+	case unicode.UTF8, charmap.Windows1252:
+		return e
+	}
+	for _, cm := range nationals[1:] {
+		if e == cm && d.Tally[RuleLanguage] >= minimum {
+			return cm
+		}
+	}
+	return charmap.CodePage437
+}
+
+// decodeZip returns the name or comment decoded to UTF-8.
+func decodeZip(e encoding.Encoding, s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	return e.NewDecoder().String(s)
+}
+
+// zipANSI returns the archive comment encoded as CP-437, where the raw comment uses the encoding
+// and the text is the comment decoded to UTF-8.
+// A comment that is CP-437, or has no evidence of another encoding, is returned unchanged,
+// otherwise it is converted using the nearest characters.
+func zipANSI(e encoding.Encoding, raw, text string) ([]byte, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if codepage.IsCodePage437(e) || zipEvidence([]byte(raw)) == charmap.CodePage437 {
+		return []byte(raw), nil
+	}
+	return EncodeLegacy([]byte(text), charmap.CodePage437, FallbackTransliterate)
+}
@@ -0,0 +1,164 @@
+package helper_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/Defacto2/helper"
+	"github.com/Defacto2/helper/ansi"
+	"github.com/Defacto2/helper/pkzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// bbsAdvert is an ANSI archive comment that uses CP-437 block characters.
+const bbsAdvert = "\x1b[1;33m\xdb\xdb Uploaded to The Bytes BBS \xdb\xdb\x1b[0m\r\n\xb0\xb1\xb2 +1 555 0100 \xb2\xb1\xb0\r\n"
+
+// zipComment returns a ZIP archive of empty files using the names, the UTF-8 flag and the archive comment.
+func zipComment(t *testing.T, comment string, utf8 bool, names ...string) (*bytes.Reader, int64) {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, name := range names {
+		fh := &zip.FileHeader{Name: name, Method: zip.Store, NonUTF8: !utf8}
+		_, err := zw.CreateHeader(fh)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.SetComment(comment))
+	require.NoError(t, zw.Close())
+	return bytes.NewReader(buf.Bytes()), int64(buf.Len())
+}
+
+func ExampleListZipFile() {
+	l, err := helper.ListZipFile(filepath.Join("pkzip", "testdata", "IMPLODED.ZIP"), helper.ZipOptions{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, e := range l.Entries[:2] {
+		fmt.Println(e.Name, e.Method, e.UncompressedSize)
+	}
+	// Output: 4K2TREES.TXT Imploded 5038
+	// 4K3TREES.TXT Imploded 5038
+}
+
+func TestListZip(t *testing.T) {
+	t.Parallel()
+	r, size := zipComment(t, bbsAdvert, false, "CAF\x90.TXT", "DOCS/", "DOCS/\x9cPRICES.TXT")
+	l, err := helper.ListZip(r, size, helper.ZipOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, charmap.CodePage437, l.Encoding)
+	require.Len(t, l.Entries, 3)
+	assert.Equal(t, "CAFÉ.TXT", l.Entries[0].Name)
+	assert.Equal(t, pkzip.Stored, l.Entries[0].Method)
+	assert.True(t, l.Entries[1].Dir)
+	assert.Equal(t, "DOCS/£PRICES.TXT", l.Entries[2].Name)
+	assert.Contains(t, l.Comment, "██ Uploaded to The Bytes BBS ██")
+	assert.Contains(t, l.Comment, "░▒▓")
+	assert.Equal(t, []byte(bbsAdvert), l.ANSI)
+
+	s := ansi.Render(l.ANSI, ansi.Options{})
+	assert.Equal(t, 2, s.Rows())
+	assert.Equal(t, byte(0xdb), s.Cell(0, 0).Char)
+	assert.Equal(t, uint8(ansi.Yellow), s.Cell(0, 0).Fore)
+}
+
+func TestListZipEncoding(t *testing.T) {
+	t.Parallel()
+	// an ASCII archive uses the default CP-437
+	r, size := zipComment(t, "", false, "README.TXT")
+	l, err := helper.ListZip(r, size, helper.ZipOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, charmap.CodePage437, l.Encoding)
+	assert.Empty(t, l.Comment)
+	assert.Nil(t, l.ANSI)
+
+	// a Windows-1252 comment is converted to CP-437 for the ANSI renderer
+	r, size = zipComment(t, "\x93Caf\xe9\x94 \x96 d\xe9j\xe0 vu \xa9 1996", false, "caf\xe9.txt")
+	l, err = helper.ListZip(r, size, helper.ZipOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, charmap.Windows1252, l.Encoding)
+	assert.Equal(t, "café.txt", l.Entries[0].Name)
+	assert.Equal(t, "“Café” – déjà vu © 1996", l.Comment)
+	assert.Equal(t, []byte("\"Caf\x82\" - d\x82j\x85 vu (C) 1996"), l.ANSI)
+
+	// the CP-437 letters and symbols between 0xa0 and 0xff are not read as ISO-8859-1
+	tests := []struct {
+		comment, name, wantComment, wantName string
+	}{
+		{
+			"Uploaded to the Graveyard BBS \xfe 14.4k \xfe\r\n", "ESPA\xa5A.TXT",
+			"Uploaded to the Graveyard BBS ■ 14.4k ■\r\n", "ESPAÑA.TXT",
+		},
+		{
+			"\xadHola! \xa8C\xa2mo est\xa0s? Ma\xa4ana a las 9\xf8", "MA\xa5ANA.TXT",
+			"¡Hola! ¿Cómo estás? Mañana a las 9°", "MAÑANA.TXT",
+		},
+		{"\xae Graveyard BBS \xaf 2 nodes \xf1 14.4k \xfe 9600 \xab price", "", "« Graveyard BBS » 2 nodes ± 14.4k ■ 9600 ½ price", ""},
+	}
+	for _, tt := range tests {
+		var names []string
+		if tt.name != "" {
+			names = append(names, tt.name)
+		}
+		r, size = zipComment(t, tt.comment, false, names...)
+		l, err = helper.ListZip(r, size, helper.ZipOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, charmap.CodePage437, l.Encoding, tt.comment)
+		assert.Equal(t, tt.wantComment, l.Comment)
+		assert.Equal(t, []byte(tt.comment), l.ANSI)
+		if tt.name != "" {
+			assert.Equal(t, tt.wantName, l.Entries[0].Name)
+		}
+	}
+
+	// the names of a national code page
+	name, err := charmap.CodePage866.NewEncoder().String("ПРИВЕТ ИЗ МОСКВЫ.TXT")
+	require.NoError(t, err)
+	r, size = zipComment(t, "", false, name)
+	l, err = helper.ListZip(r, size, helper.ZipOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, charmap.CodePage866, l.Encoding)
+	assert.Equal(t, "ПРИВЕТ ИЗ МОСКВЫ.TXT", l.Entries[0].Name)
+
+	// a comment without evidence keeps its raw bytes, even with a forced encoding
+	r, size = zipComment(t, "Graveyard BBS \xfe", false)
+	l, err = helper.ListZip(r, size, helper.ZipOptions{Encoding: charmap.ISO8859_1})
+	require.NoError(t, err)
+	assert.Equal(t, "Graveyard BBS þ", l.Comment)
+	assert.Equal(t, []byte("Graveyard BBS \xfe"), l.ANSI)
+
+	// a forced encoding, where 0x9b is ø rather than ¢
+	r, size = zipComment(t, "", false, "L\x9bVE.TXT")
+	l, err = helper.ListZip(r, size, helper.ZipOptions{Encoding: charmap.CodePage865})
+	require.NoError(t, err)
+	assert.Equal(t, "LøVE.TXT", l.Entries[0].Name)
+
+	// names marked as UTF-8 are not decoded
+	r, size = zipComment(t, "", true, "café.txt")
+	l, err = helper.ListZip(r, size, helper.ZipOptions{Encoding: charmap.CodePage437})
+	require.NoError(t, err)
+	assert.Equal(t, "café.txt", l.Entries[0].Name)
+
+	// unmarked UTF-8 names have more evidence than CP-437
+	r, size = zipComment(t, "", false, "café.txt", "naïve.txt")
+	l, err = helper.ListZip(r, size, helper.ZipOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, unicode.UTF8, l.Encoding)
+	assert.Equal(t, "naïve.txt", l.Entries[1].Name)
+}
+
+func TestListZipErrors(t *testing.T) {
+	t.Parallel()
+	_, err := helper.ListZip(nil, 0, helper.ZipOptions{})
+	require.ErrorIs(t, err, helper.ErrReader)
+	_, err = helper.ListZip(bytes.NewReader([]byte("PK")), 2, helper.ZipOptions{})
+	require.Error(t, err)
+	_, err = helper.ListZipFile("nosuchfile", helper.ZipOptions{})
+	require.Error(t, err)
+}